@@ -0,0 +1,252 @@
+package cqrs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sync"
+	"time"
+
+	"go.uber.org/multierr"
+)
+
+type Auditable interface {
+	Audited()
+}
+
+type AuditRecord struct {
+	PrincipalID   string      `json:"principal_id,omitempty"`
+	CommandType   string      `json:"command_type"`
+	HandlerType   string      `json:"handler_type,omitempty"`
+	Payload       interface{} `json:"payload"`
+	Result        interface{} `json:"result,omitempty"`
+	Outcome       string      `json:"outcome"`
+	Error         string      `json:"error,omitempty"`
+	MessageID     string      `json:"message_id,omitempty"`
+	CorrelationID string      `json:"correlation_id,omitempty"`
+	CausationID   string      `json:"causation_id,omitempty"`
+	StartedAt     time.Time   `json:"started_at"`
+	CompletedAt   time.Time   `json:"completed_at"`
+}
+
+type AuditSink interface {
+	Write(ctx context.Context, record AuditRecord) error
+}
+
+var ErrAuditFailed = errors.New("audit record could not be written")
+
+type AuditError struct {
+	MessageIDs
+	CommandType string
+	Err         error
+}
+
+func (e *AuditError) Error() string {
+	return fmt.Sprintf("audit record for command of type %s could not be written: %v", e.CommandType, e.Err)
+}
+
+func (e *AuditError) Unwrap() []error {
+	return []error{ErrAuditFailed, e.Err}
+}
+
+type AuditOption func(behavior *AuditBehavior)
+
+func AuditCommand[TCommand any]() AuditOption {
+	var command TCommand
+	commandType := reflect.TypeOf(command)
+
+	return func(behavior *AuditBehavior) {
+		behavior.commands[commandType] = struct{}{}
+	}
+}
+
+func AuditResults(enabled bool) AuditOption {
+	return func(behavior *AuditBehavior) {
+		behavior.results = enabled
+	}
+}
+
+type AuditBehavior struct {
+	sink     AuditSink
+	commands map[reflect.Type]struct{}
+	results  bool
+	now      func() time.Time
+}
+
+func NewAuditBehavior(sink AuditSink, options ...AuditOption) (*AuditBehavior, error) {
+	if sink == nil {
+		return nil, errors.New("an audit sink must be provided")
+	}
+
+	behavior := &AuditBehavior{
+		sink:     sink,
+		commands: make(map[reflect.Type]struct{}),
+		now:      time.Now,
+	}
+
+	for _, option := range options {
+		option(behavior)
+	}
+
+	return behavior, nil
+}
+
+func (b *AuditBehavior) Handle(ctx context.Context, request interface{}, next PipelineFunc) (res interface{}, err error) {
+	if !b.audited(request) {
+		return next(ctx, request)
+	}
+
+	record := b.newRecord(ctx, request)
+
+	defer func() {
+		p := recover()
+
+		b.complete(&record, res, err, p)
+
+		writeErr := b.sink.Write(ctx, record)
+
+		if p != nil {
+			panic(p)
+		}
+
+		if writeErr != nil {
+			err = multierr.Append(err, &AuditError{MessageIDs: MessageIDsFromContext(ctx), CommandType: record.CommandType, Err: writeErr})
+		}
+	}()
+
+	return next(ctx, request)
+}
+
+func (b *AuditBehavior) audited(request interface{}) bool {
+	if _, ok := request.(Auditable); ok {
+		return true
+	}
+
+	_, found := b.commands[reflect.TypeOf(request)]
+
+	return found
+}
+
+func (b *AuditBehavior) CheckBatchItem(ctx context.Context, request interface{}) error {
+	if b.audited(request) {
+		return batchNotSupported(request, b)
+	}
+
+	return nil
+}
+
+func (b *AuditBehavior) newRecord(ctx context.Context, request interface{}) AuditRecord {
+	info, _ := DispatchInfoFromContext(ctx)
+
+	record := AuditRecord{
+		CommandType: reflect.TypeOf(request).String(),
+		HandlerType: info.HandlerType,
+		Payload:     Redact(request),
+		StartedAt:   b.now(),
+	}
+
+	if principal, ok := PrincipalFromContext(ctx); ok {
+		record.PrincipalID = principal.GetID()
+	}
+
+	ids := MessageIDsFromContext(ctx)
+	record.MessageID = ids.MessageID
+	record.CorrelationID = ids.CorrelationID
+	record.CausationID = ids.CausationID
+
+	return record
+}
+
+func (b *AuditBehavior) complete(record *AuditRecord, res interface{}, err error, p interface{}) {
+	record.CompletedAt = b.now()
+
+	switch {
+	case p != nil:
+		record.Outcome = OutcomePanic
+		record.Error = fmt.Sprint(p)
+	case err != nil:
+		record.Outcome = OutcomeError
+		record.Error = err.Error()
+	default:
+		record.Outcome = OutcomeSuccess
+		if b.results {
+			record.Result = Redact(res)
+		}
+	}
+}
+
+type MemoryAuditSink struct {
+	mutex   sync.Mutex
+	records []AuditRecord
+}
+
+func NewMemoryAuditSink() *MemoryAuditSink {
+	return &MemoryAuditSink{}
+}
+
+func (s *MemoryAuditSink) Write(ctx context.Context, record AuditRecord) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.records = append(s.records, record)
+
+	return nil
+}
+
+func (s *MemoryAuditSink) Records() []AuditRecord {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	records := make([]AuditRecord, len(s.records))
+	copy(records, s.records)
+
+	return records
+}
+
+type JSONLinesAuditSink struct {
+	mutex   sync.Mutex
+	writer  io.Writer
+	encoder *json.Encoder
+}
+
+func NewJSONLinesAuditSink(writer io.Writer) (*JSONLinesAuditSink, error) {
+	if writer == nil {
+		return nil, errors.New("a writer must be provided")
+	}
+
+	return &JSONLinesAuditSink{
+		writer:  writer,
+		encoder: json.NewEncoder(writer),
+	}, nil
+}
+
+func OpenJSONLinesAuditSink(path string) (*JSONLinesAuditSink, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return NewJSONLinesAuditSink(file)
+}
+
+func (s *JSONLinesAuditSink) Write(ctx context.Context, record AuditRecord) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.encoder.Encode(record)
+}
+
+func (s *JSONLinesAuditSink) Close() error {
+	closer, ok := s.writer.(io.Closer)
+
+	if !ok {
+		return nil
+	}
+
+	return closer.Close()
+}
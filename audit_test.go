@@ -0,0 +1,188 @@
+package cqrs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type TransferFunds struct {
+	Account string
+	Pin     string `cqrs:"sensitive"`
+}
+
+func (c *TransferFunds) Audited() {
+}
+
+type TransferFundsHandler struct {
+}
+
+func (h *TransferFundsHandler) Handle(ctx context.Context, command *TransferFunds) (*Response, error) {
+	return &Response{}, nil
+}
+
+type FailingAuditSink struct {
+}
+
+func (s *FailingAuditSink) Write(ctx context.Context, record AuditRecord) error {
+	return errors.New("disk full")
+}
+
+func newAuditBehavior(t *testing.T, sink AuditSink, options ...AuditOption) *AuditBehavior {
+	behavior, err := NewAuditBehavior(sink, options...)
+	assert.Nil(t, err)
+	return behavior
+}
+
+func TestAuditBehavior_WhenCommandIsAuditable_ShouldWriteRecord(t *testing.T) {
+	// arrange
+	defer commands_cleanup(t)
+	defer behaviors_cleanup(t)
+	sink := NewMemoryAuditSink()
+	RegisterCommandHandler[*TransferFunds, *Response](&TransferFundsHandler{})
+	RegisterCommandPipelineBehavior(0, newAuditBehavior(t, sink))
+	ctx := WithPrincipal(context.TODO(), &FakePrincipal{ID: "user-1"})
+	ctx = WithCorrelationID(ctx, "correlation-1")
+
+	// act
+	_, err := Send[*TransferFunds, *Response](ctx, &TransferFunds{Account: "123", Pin: "0000"})
+
+	// assert
+	records := sink.Records()
+	assert.Nil(t, err)
+	assert.Len(t, records, 1)
+	assert.Equal(t, "user-1", records[0].PrincipalID)
+	assert.Equal(t, "*cqrs.TransferFunds", records[0].CommandType)
+	assert.Equal(t, "*cqrs.TransferFundsHandler", records[0].HandlerType)
+	assert.Equal(t, "correlation-1", records[0].CorrelationID)
+	assert.Equal(t, map[string]interface{}{"Account": "123", "Pin": RedactedValue}, records[0].Payload)
+	assert.Equal(t, OutcomeSuccess, records[0].Outcome)
+	assert.False(t, records[0].CompletedAt.Before(records[0].StartedAt))
+}
+
+func TestAuditBehavior_WhenCommandIsNotAuditable_ShouldNotWriteRecord(t *testing.T) {
+	// arrange
+	sink := NewMemoryAuditSink()
+	behavior := newAuditBehavior(t, sink)
+
+	// act
+	behavior.Handle(context.TODO(), &Command1{}, succeedingNext)
+
+	// assert
+	assert.Empty(t, sink.Records())
+}
+
+func TestAuditBehavior_WhenCommandTypeRegistered_ShouldWriteRecord(t *testing.T) {
+	// arrange
+	sink := NewMemoryAuditSink()
+	behavior := newAuditBehavior(t, sink, AuditCommand[*Command1](), AuditResults(true))
+
+	// act
+	behavior.Handle(context.TODO(), &Command1{}, succeedingNext)
+
+	// assert
+	records := sink.Records()
+	assert.Len(t, records, 1)
+	assert.Equal(t, map[string]interface{}{}, records[0].Result)
+}
+
+func TestAuditBehavior_WhenHandlerFails_ShouldRecordError(t *testing.T) {
+	// arrange
+	sink := NewMemoryAuditSink()
+	behavior := newAuditBehavior(t, sink)
+
+	// act
+	_, err := behavior.Handle(context.TODO(), &TransferFunds{}, failingNext)
+
+	// assert
+	records := sink.Records()
+	assert.Error(t, err)
+	assert.Equal(t, OutcomeError, records[0].Outcome)
+	assert.Equal(t, "dependency unavailable", records[0].Error)
+}
+
+func TestAuditBehavior_WhenHandlerPanics_ShouldRecordPanicAndPanic(t *testing.T) {
+	// arrange
+	sink := NewMemoryAuditSink()
+	behavior := newAuditBehavior(t, sink)
+	next := func(ctx context.Context, request interface{}) (interface{}, error) {
+		panic("boom")
+	}
+
+	// act
+	handle := func() {
+		behavior.Handle(context.TODO(), &TransferFunds{}, next)
+	}
+
+	// assert
+	assert.PanicsWithValue(t, "boom", handle)
+	assert.Equal(t, OutcomePanic, sink.Records()[0].Outcome)
+}
+
+func TestAuditBehavior_WhenSinkFails_ShouldReturnAuditError(t *testing.T) {
+	// arrange
+	behavior := newAuditBehavior(t, &FailingAuditSink{})
+
+	// act
+	res, err := behavior.Handle(context.TODO(), &TransferFunds{}, succeedingNext)
+
+	// assert
+	var auditErr *AuditError
+	assert.True(t, errors.Is(err, ErrAuditFailed))
+	assert.True(t, errors.As(err, &auditErr))
+	assert.Equal(t, "*cqrs.TransferFunds", auditErr.CommandType)
+	assert.NotNil(t, res)
+}
+
+func TestNewAuditBehavior_WhenSinkIsNil_ShouldReturnError(t *testing.T) {
+	// act
+	_, err := NewAuditBehavior(nil)
+
+	// assert
+	assert.Error(t, err)
+}
+
+func TestJSONLinesAuditSink_WhenRecordsWritten_ShouldWriteOneLinePerRecord(t *testing.T) {
+	// arrange
+	buffer := &bytes.Buffer{}
+	sink, _ := NewJSONLinesAuditSink(buffer)
+	started := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// act
+	sink.Write(context.TODO(), AuditRecord{CommandType: "a", Outcome: OutcomeSuccess, StartedAt: started})
+	sink.Write(context.TODO(), AuditRecord{CommandType: "b", Outcome: OutcomeError, Error: "failed"})
+
+	// assert
+	lines := bytes.Split(bytes.TrimSpace(buffer.Bytes()), []byte("\n"))
+	assert.Len(t, lines, 2)
+	record := AuditRecord{}
+	assert.Nil(t, json.Unmarshal(lines[1], &record))
+	assert.Equal(t, "b", record.CommandType)
+	assert.Equal(t, "failed", record.Error)
+	assert.Contains(t, string(lines[0]), `"started_at":"2024-01-01T00:00:00Z"`)
+}
+
+func TestOpenJSONLinesAuditSink_WhenReopened_ShouldAppendToFile(t *testing.T) {
+	// arrange
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	first, _ := OpenJSONLinesAuditSink(path)
+	first.Write(context.TODO(), AuditRecord{CommandType: "a"})
+	first.Close()
+
+	// act
+	second, err := OpenJSONLinesAuditSink(path)
+	second.Write(context.TODO(), AuditRecord{CommandType: "b"})
+	second.Close()
+
+	// assert
+	content, _ := os.ReadFile(path)
+	assert.Nil(t, err)
+	assert.Len(t, bytes.Split(bytes.TrimSpace(content), []byte("\n")), 2)
+}
@@ -0,0 +1,167 @@
+package cqrs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+type Principal interface {
+	GetID() string
+}
+
+type principalContextKey struct{}
+
+func WithPrincipal(ctx context.Context, principal Principal) context.Context {
+	return context.WithValue(ctx, principalContextKey{}, principal)
+}
+
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+	principal, ok := ctx.Value(principalContextKey{}).(Principal)
+	return principal, ok && principal != nil
+}
+
+var ErrUnauthenticated = errors.New("unauthenticated")
+var ErrForbidden = errors.New("forbidden")
+
+type AuthorizationError struct {
+	MessageIDs
+	RequestType string
+	PrincipalID string
+	err         error
+}
+
+func (e *AuthorizationError) Error() string {
+	if e.PrincipalID == "" {
+		return fmt.Sprintf("%s: request of type %s requires an authenticated principal", e.err.Error(), e.RequestType)
+	}
+
+	return fmt.Sprintf("%s: principal %s is not allowed to issue request of type %s", e.err.Error(), e.PrincipalID, e.RequestType)
+}
+
+func (e *AuthorizationError) Unwrap() error {
+	return e.err
+}
+
+type Authorizable interface {
+	Authorize(ctx context.Context, principal Principal) (bool, error)
+}
+
+type IAuthorizationPolicy[TRequest any] interface {
+	Authorize(ctx context.Context, principal Principal, request TRequest) (bool, error)
+}
+
+var authorizationPolicies map[reflect.Type][]interface{}
+
+func init() {
+	authorizationPolicies = make(map[reflect.Type][]interface{})
+}
+
+func RegisterAuthorizationPolicy[TRequest any](policy IAuthorizationPolicy[TRequest]) error {
+	if policy == nil {
+		return errors.New("an authorization policy must be provided")
+	}
+
+	var request TRequest
+	requestType := reflect.TypeOf(request)
+
+	authorizationPolicies[requestType] = append(authorizationPolicies[requestType], policy)
+
+	return nil
+}
+
+type AuthorizationBehavior struct {
+}
+
+func NewAuthorizationBehavior() *AuthorizationBehavior {
+	return &AuthorizationBehavior{}
+}
+
+func (b *AuthorizationBehavior) Handle(ctx context.Context, request interface{}, next PipelineFunc) (interface{}, error) {
+	err := Authorize(ctx, request)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return next(ctx, request)
+}
+
+func (b *AuthorizationBehavior) CheckBatchItem(ctx context.Context, request interface{}) error {
+	return Authorize(ctx, request)
+}
+
+type StreamAuthorizationBehavior struct {
+}
+
+func NewStreamAuthorizationBehavior() *StreamAuthorizationBehavior {
+	return &StreamAuthorizationBehavior{}
+}
+
+func (b *StreamAuthorizationBehavior) Handle(ctx context.Context, query interface{}, emit StreamEmitFunc, next StreamFunc) error {
+	err := Authorize(ctx, query)
+
+	if err != nil {
+		return err
+	}
+
+	return next(ctx, query, emit)
+}
+
+func Authorize(ctx context.Context, request interface{}) error {
+	requestType := reflect.TypeOf(request)
+	policies := authorizationPolicies[requestType]
+	authorizable, isAuthorizable := request.(Authorizable)
+
+	if !isAuthorizable && len(policies) <= 0 {
+		return nil
+	}
+
+	principal, ok := PrincipalFromContext(ctx)
+
+	if !ok {
+		return &AuthorizationError{MessageIDs: MessageIDsFromContext(ctx), RequestType: requestType.String(), err: ErrUnauthenticated}
+	}
+
+	forbidden := &AuthorizationError{
+		MessageIDs:  MessageIDsFromContext(ctx),
+		RequestType: requestType.String(),
+		PrincipalID: principal.GetID(),
+		err:         ErrForbidden,
+	}
+
+	if isAuthorizable {
+		allowed, err := authorizable.Authorize(ctx, principal)
+
+		if err != nil {
+			return err
+		}
+
+		if !allowed {
+			return forbidden
+		}
+	}
+
+	args := []reflect.Value{
+		reflect.ValueOf(ctx),
+		reflect.ValueOf(&principal).Elem(),
+		reflect.ValueOf(request),
+	}
+
+	for _, policy := range policies {
+		r := reflect.ValueOf(policy).MethodByName("Authorize").Call(args)
+
+		authorizeErr, _ := r[1].Interface().(error)
+
+		if authorizeErr != nil {
+			return authorizeErr
+		}
+
+		if !r[0].Bool() {
+			return forbidden
+		}
+	}
+
+	return nil
+}
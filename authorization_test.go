@@ -0,0 +1,177 @@
+package cqrs
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type FakePrincipal struct {
+	ID    string
+	Roles []string
+}
+
+func (p *FakePrincipal) GetID() string {
+	return p.ID
+}
+
+type DeleteProduct struct {
+	OwnerID string
+}
+
+func (c *DeleteProduct) Authorize(ctx context.Context, principal Principal) (bool, error) {
+	return principal.GetID() == c.OwnerID, nil
+}
+
+type DeleteProductHandler struct {
+	calls int
+}
+
+func (h *DeleteProductHandler) Handle(ctx context.Context, command *DeleteProduct) (*Response, error) {
+	h.calls++
+	return &Response{}, nil
+}
+
+type AdminPolicy struct {
+}
+
+func (p *AdminPolicy) Authorize(ctx context.Context, principal Principal, query *Query1) (bool, error) {
+	for _, role := range principal.(*FakePrincipal).Roles {
+		if role == "admin" {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+type BrokenPolicy struct {
+}
+
+func (p *BrokenPolicy) Authorize(ctx context.Context, principal Principal, query *Query1) (bool, error) {
+	return false, errors.New("policy store unavailable")
+}
+
+func authorization_cleanup(t *testing.T) {
+	t.Cleanup(func() {
+		authorizationPolicies = make(map[reflect.Type][]interface{})
+	})
+}
+
+func TestRegisterAuthorizationPolicy_WhenPolicyProvided_ShouldAddPolicyToMap(t *testing.T) {
+	// arrange
+	defer authorization_cleanup(t)
+	var query *Query1
+	requestType := reflect.TypeOf(query)
+	policy := &AdminPolicy{}
+
+	// act
+	err := RegisterAuthorizationPolicy[*Query1](policy)
+
+	// assert
+	assert.Nil(t, err)
+	assert.Contains(t, authorizationPolicies[requestType], policy)
+}
+
+func TestAuthorizationBehavior_WhenPrincipalIsMissing_ShouldReturnErrUnauthenticated(t *testing.T) {
+	// arrange
+	defer commands_cleanup(t)
+	defer behaviors_cleanup(t)
+	handler := &DeleteProductHandler{}
+	RegisterCommandHandler[*DeleteProduct, *Response](handler)
+	RegisterCommandPipelineBehavior(0, NewAuthorizationBehavior())
+
+	// act
+	_, err := Send[*DeleteProduct, *Response](context.TODO(), &DeleteProduct{OwnerID: "1"})
+
+	// assert
+	assert.True(t, errors.Is(err, ErrUnauthenticated))
+	assert.Equal(t, 0, handler.calls)
+}
+
+func TestAuthorizationBehavior_WhenRequestDeniesPrincipal_ShouldReturnErrForbidden(t *testing.T) {
+	// arrange
+	defer commands_cleanup(t)
+	defer behaviors_cleanup(t)
+	handler := &DeleteProductHandler{}
+	RegisterCommandHandler[*DeleteProduct, *Response](handler)
+	RegisterCommandPipelineBehavior(0, NewAuthorizationBehavior())
+	ctx := WithPrincipal(context.TODO(), &FakePrincipal{ID: "2"})
+
+	// act
+	_, err := Send[*DeleteProduct, *Response](ctx, &DeleteProduct{OwnerID: "1"})
+
+	// assert
+	var authErr *AuthorizationError
+	assert.True(t, errors.Is(err, ErrForbidden))
+	assert.True(t, errors.As(err, &authErr))
+	assert.Equal(t, "2", authErr.PrincipalID)
+	assert.Equal(t, 0, handler.calls)
+}
+
+func TestAuthorizationBehavior_WhenRequestAllowsPrincipal_ShouldCallHandler(t *testing.T) {
+	// arrange
+	defer commands_cleanup(t)
+	defer behaviors_cleanup(t)
+	handler := &DeleteProductHandler{}
+	RegisterCommandHandler[*DeleteProduct, *Response](handler)
+	RegisterCommandPipelineBehavior(0, NewAuthorizationBehavior())
+	ctx := WithPrincipal(context.TODO(), &FakePrincipal{ID: "1"})
+
+	// act
+	_, err := Send[*DeleteProduct, *Response](ctx, &DeleteProduct{OwnerID: "1"})
+
+	// assert
+	assert.Nil(t, err)
+	assert.Equal(t, 1, handler.calls)
+}
+
+func TestAuthorize_WhenPolicyDeniesPrincipal_ShouldReturnErrForbidden(t *testing.T) {
+	// arrange
+	defer authorization_cleanup(t)
+	RegisterAuthorizationPolicy[*Query1](&AdminPolicy{})
+	ctx := WithPrincipal(context.TODO(), &FakePrincipal{ID: "1", Roles: []string{"user"}})
+
+	// act
+	err := Authorize(ctx, &Query1{})
+
+	// assert
+	assert.True(t, errors.Is(err, ErrForbidden))
+}
+
+func TestAuthorize_WhenPolicyAllowsPrincipal_ShouldNotReturnError(t *testing.T) {
+	// arrange
+	defer authorization_cleanup(t)
+	RegisterAuthorizationPolicy[*Query1](&AdminPolicy{})
+	ctx := WithPrincipal(context.TODO(), &FakePrincipal{ID: "1", Roles: []string{"admin"}})
+
+	// act
+	err := Authorize(ctx, &Query1{})
+
+	// assert
+	assert.Nil(t, err)
+}
+
+func TestAuthorize_WhenPolicyFails_ShouldReturnPolicyError(t *testing.T) {
+	// arrange
+	defer authorization_cleanup(t)
+	RegisterAuthorizationPolicy[*Query1](&BrokenPolicy{})
+	ctx := WithPrincipal(context.TODO(), &FakePrincipal{ID: "1"})
+
+	// act
+	err := Authorize(ctx, &Query1{})
+
+	// assert
+	assert.EqualError(t, err, "policy store unavailable")
+}
+
+func TestAuthorize_WhenNoPolicies_ShouldAllowAnonymousRequests(t *testing.T) {
+	// act
+	err := Authorize(context.TODO(), &Command1{})
+
+	// assert
+	assert.Nil(t, err)
+}
@@ -0,0 +1,297 @@
+package cqrs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"go.uber.org/multierr"
+)
+
+type BatchItemResult[TResponse any] struct {
+	Response TResponse
+	Err      error
+}
+
+type BatchResult[TResponse any] []BatchItemResult[TResponse]
+
+func (r BatchResult[TResponse]) Responses() []TResponse {
+	responses := make([]TResponse, len(r))
+
+	for i, item := range r {
+		responses[i] = item.Response
+	}
+
+	return responses
+}
+
+func (r BatchResult[TResponse]) Failed() int {
+	failed := 0
+
+	for _, item := range r {
+		if item.Err != nil {
+			failed++
+		}
+	}
+
+	return failed
+}
+
+func (r BatchResult[TResponse]) succeeded() []interface{} {
+	responses := []interface{}{}
+
+	for _, item := range r {
+		if item.Err == nil {
+			responses = append(responses, item.Response)
+		}
+	}
+
+	return responses
+}
+
+func (r BatchResult[TResponse]) Err() error {
+	var err error
+
+	for _, item := range r {
+		err = multierr.Append(err, item.Err)
+	}
+
+	return err
+}
+
+type batchResponses interface {
+	succeeded() []interface{}
+}
+
+type IBatchCommandHandler[TCommand any, TResponse any] interface {
+	HandleBatch(ctx context.Context, commands []TCommand) (BatchResult[TResponse], error)
+}
+
+var ErrBatchNotSupported = errors.New("request can't be handled by a batch handler")
+
+// a behavior that runs once for a whole batch checks every item first, behaviors
+// without the check make the batch go item by item through the full pipeline
+type IBatchItemBehavior interface {
+	CheckBatchItem(ctx context.Context, request interface{}) error
+}
+
+var batchCommandHandlers map[reflect.Type]interface{}
+
+func init() {
+	batchCommandHandlers = make(map[reflect.Type]interface{})
+}
+
+func RegisterBatchCommandHandler[TCommand any, TResponse any](handler IBatchCommandHandler[TCommand, TResponse]) error {
+	var command TCommand
+	commandType := reflect.TypeOf(command)
+
+	_, found := batchCommandHandlers[commandType]
+
+	if found {
+		msg := fmt.Sprintf("batch handler for command of type %s is already registered", commandType.String())
+		return errors.New(msg)
+	}
+
+	batchCommandHandlers[commandType] = handler
+
+	return nil
+}
+
+type BatchOption func(options *batchOptions)
+
+type batchOptions struct {
+	concurrency int
+}
+
+func BatchConcurrency(concurrency int) BatchOption {
+	return func(options *batchOptions) {
+		options.concurrency = concurrency
+	}
+}
+
+func SendBatch[TCommand any, TResponse any](ctx context.Context, commands []TCommand, options ...BatchOption) (BatchResult[TResponse], error) {
+	if len(commands) <= 0 {
+		return BatchResult[TResponse]{}, nil
+	}
+
+	var command TCommand
+	commandType := reflect.TypeOf(command)
+	h, hasBatchHandler := batchCommandHandlers[commandType]
+	blocker := batchBlocker(commandBehaviors)
+
+	if hasBatchHandler && blocker == nil {
+		return sendBatch[TCommand, TResponse](ctx, h, commands)
+	}
+
+	if _, found := commandHandlers[commandType]; !found {
+		if hasBatchHandler {
+			return nil, batchNotSupported(command, blocker)
+		}
+
+		msg := fmt.Sprintf("no handler registered for command %s", commandType)
+		return nil, errors.New(msg)
+	}
+
+	batch := &batchOptions{
+		concurrency: 1,
+	}
+
+	for _, option := range options {
+		option(batch)
+	}
+
+	return sendEach[TCommand, TResponse](ctx, commands, batch.concurrency), nil
+}
+
+func sendBatch[TCommand any, TResponse any](ctx context.Context, h interface{}, commands []TCommand) (BatchResult[TResponse], error) {
+	handler, casted := h.(IBatchCommandHandler[TCommand, TResponse])
+
+	if !casted {
+		msg := fmt.Sprintf("batch handler of type %T is not assignable for command of type %T and response of type %T", h, *new(TCommand), *new(TResponse))
+		return nil, errors.New(msg)
+	}
+
+	result := make(BatchResult[TResponse], len(commands))
+	accepted := make([]TCommand, 0, len(commands))
+	positions := make([]int, 0, len(commands))
+
+	for i, command := range commands {
+		if err := checkBatchItem(ctx, commandBehaviors, command); err != nil {
+			result[i].Err = err
+			continue
+		}
+
+		accepted = append(accepted, command)
+		positions = append(positions, i)
+	}
+
+	if len(accepted) == 0 {
+		return result, nil
+	}
+
+	batchHandle := func(ctx context.Context, request interface{}) (interface{}, error) {
+		commands, casted := request.([]TCommand)
+
+		if !casted {
+			msg := fmt.Sprintf("batch of type %T can't be replaced by a request of type %T", *new([]TCommand), request)
+			return nil, errors.New(msg)
+		}
+
+		return handler.HandleBatch(ctx, commands)
+	}
+
+	info := DispatchInfo{
+		Kind:        CommandMessage,
+		MessageType: reflect.TypeOf(commands).String(),
+		HandlerType: handlerName(h),
+	}
+
+	res, err := dispatch(ctx, info, accepted, commandBehaviors, batchHandle)
+
+	if err != nil {
+		return nil, err
+	}
+
+	handled, casted := res.(BatchResult[TResponse])
+
+	if !casted || len(handled) != len(accepted) {
+		msg := fmt.Sprintf("batch handler of type %T must return one result per command", h)
+		return nil, errors.New(msg)
+	}
+
+	for i, item := range handled {
+		result[positions[i]] = item
+	}
+
+	return result, nil
+}
+
+// a batch is configured like the commands or queries in it
+func lookupRequestType[TValue any](values map[reflect.Type]TValue, request interface{}) (TValue, bool) {
+	requestType := reflect.TypeOf(request)
+	value, found := values[requestType]
+
+	if !found && requestType != nil && requestType.Kind() == reflect.Slice {
+		value, found = values[requestType.Elem()]
+	}
+
+	return value, found
+}
+
+func batchBlocker(behaviors map[int]interface{}) interface{} {
+	for _, behavior := range sortBehaviors(behaviors) {
+		if _, ok := behavior.(IBatchItemBehavior); !ok {
+			return behavior
+		}
+	}
+
+	return nil
+}
+
+func checkBatchItem(ctx context.Context, behaviors map[int]interface{}, request interface{}) error {
+	for _, behavior := range sortBehaviors(behaviors) {
+		if err := behavior.(IBatchItemBehavior).CheckBatchItem(ctx, request); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func batchNotSupported(request interface{}, behavior interface{}) error {
+	return fmt.Errorf("%w: %T needs the %T behavior for each item", ErrBatchNotSupported, request, behavior)
+}
+
+func sendEach[TCommand any, TResponse any](ctx context.Context, commands []TCommand, concurrency int) BatchResult[TResponse] {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	result := make(BatchResult[TResponse], len(commands))
+	semaphore := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	var panicOnce sync.Once
+	var panicked interface{}
+
+	for i, command := range commands {
+		if ctx.Err() != nil {
+			result[i].Err = ctx.Err()
+			continue
+		}
+
+		select {
+		case semaphore <- struct{}{}:
+		case <-ctx.Done():
+			result[i].Err = ctx.Err()
+			continue
+		}
+
+		wg.Add(1)
+
+		go func(i int, command TCommand) {
+			defer wg.Done()
+			defer func() {
+				<-semaphore
+			}()
+			defer func() {
+				if p := recover(); p != nil {
+					panicOnce.Do(func() {
+						panicked = p
+					})
+				}
+			}()
+
+			result[i].Response, result[i].Err = Send[TCommand, TResponse](ctx, command)
+		}(i, command)
+	}
+
+	wg.Wait()
+
+	if panicked != nil {
+		panic(panicked)
+	}
+
+	return result
+}
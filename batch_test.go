@@ -0,0 +1,314 @@
+package cqrs
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type ImportRecord struct {
+	ID   int
+	Fail bool
+}
+
+type ImportRecordHandler struct {
+	mutex     sync.Mutex
+	active    int
+	maxActive int
+	delay     time.Duration
+	calls     int
+}
+
+func (h *ImportRecordHandler) Handle(ctx context.Context, command *ImportRecord) (int, error) {
+	h.mutex.Lock()
+	h.calls++
+	h.active++
+	if h.active > h.maxActive {
+		h.maxActive = h.active
+	}
+	h.mutex.Unlock()
+
+	time.Sleep(h.delay)
+
+	h.mutex.Lock()
+	h.active--
+	h.mutex.Unlock()
+
+	if command.Fail {
+		return 0, errors.New("invalid record")
+	}
+
+	return command.ID, nil
+}
+
+type ImportRecordBatchHandler struct {
+	batches [][]*ImportRecord
+	short   bool
+}
+
+func (h *ImportRecordBatchHandler) HandleBatch(ctx context.Context, commands []*ImportRecord) (BatchResult[int], error) {
+	h.batches = append(h.batches, commands)
+
+	if h.short {
+		return BatchResult[int]{}, nil
+	}
+
+	result := make(BatchResult[int], len(commands))
+
+	for i, command := range commands {
+		result[i].Response = command.ID * 10
+	}
+
+	return result, nil
+}
+
+type CountingBehavior struct {
+	requests []interface{}
+}
+
+func (b *CountingBehavior) Handle(ctx context.Context, request interface{}, next PipelineFunc) (interface{}, error) {
+	b.requests = append(b.requests, request)
+	return next(ctx, request)
+}
+
+func (b *CountingBehavior) CheckBatchItem(ctx context.Context, request interface{}) error {
+	return nil
+}
+
+type ImportRecordValidator struct {
+}
+
+func (v *ImportRecordValidator) Validate(ctx context.Context, command *ImportRecord) error {
+	if command.ID <= 0 {
+		return FieldError{Field: "ID", Message: "must be positive"}
+	}
+
+	return nil
+}
+
+type ImportRecordPolicy struct {
+}
+
+func (p *ImportRecordPolicy) Authorize(ctx context.Context, principal Principal, command *ImportRecord) (bool, error) {
+	return false, nil
+}
+
+func batch_cleanup(t *testing.T) {
+	t.Cleanup(func() {
+		batchCommandHandlers = make(map[reflect.Type]interface{})
+	})
+}
+
+func TestSendBatch_WhenNoBatchHandler_ShouldSendEachCommand(t *testing.T) {
+	// arrange
+	defer commands_cleanup(t)
+	defer behaviors_cleanup(t)
+	behavior := &CountingBehavior{}
+	RegisterCommandHandler[*ImportRecord, int](&ImportRecordHandler{})
+	RegisterCommandPipelineBehavior(0, behavior)
+	commands := []*ImportRecord{{ID: 1}, {ID: 2, Fail: true}, {ID: 3}}
+
+	// act
+	result, err := SendBatch[*ImportRecord, int](context.TODO(), commands)
+
+	// assert
+	assert.Nil(t, err)
+	assert.Equal(t, []int{1, 0, 3}, result.Responses())
+	assert.Nil(t, result[0].Err)
+	assert.EqualError(t, result[1].Err, "invalid record")
+	assert.Equal(t, 1, result.Failed())
+	assert.Error(t, result.Err())
+	assert.Len(t, behavior.requests, 3)
+}
+
+func TestSendBatch_WhenConcurrencyConfigured_ShouldLimitConcurrentSends(t *testing.T) {
+	// arrange
+	defer commands_cleanup(t)
+	handler := &ImportRecordHandler{delay: 10 * time.Millisecond}
+	RegisterCommandHandler[*ImportRecord, int](handler)
+	commands := []*ImportRecord{{ID: 1}, {ID: 2}, {ID: 3}, {ID: 4}, {ID: 5}, {ID: 6}}
+
+	// act
+	result, _ := SendBatch[*ImportRecord, int](context.TODO(), commands, BatchConcurrency(2))
+
+	// assert
+	assert.Equal(t, []int{1, 2, 3, 4, 5, 6}, result.Responses())
+	assert.Equal(t, 2, handler.maxActive)
+}
+
+func TestSendBatch_WhenContextCanceled_ShouldFailRemainingCommands(t *testing.T) {
+	// arrange
+	defer commands_cleanup(t)
+	handler := &ImportRecordHandler{}
+	RegisterCommandHandler[*ImportRecord, int](handler)
+	ctx, cancel := context.WithCancel(context.TODO())
+	cancel()
+
+	// act
+	result, err := SendBatch[*ImportRecord, int](ctx, []*ImportRecord{{ID: 1}, {ID: 2}})
+
+	// assert
+	assert.Nil(t, err)
+	assert.Equal(t, 2, result.Failed())
+	assert.ErrorIs(t, result[0].Err, context.Canceled)
+	assert.Equal(t, 0, handler.calls)
+}
+
+func TestSendBatch_WhenBatchHandlerRegistered_ShouldRunPipelineOnce(t *testing.T) {
+	// arrange
+	defer commands_cleanup(t)
+	defer behaviors_cleanup(t)
+	defer batch_cleanup(t)
+	behavior := &CountingBehavior{}
+	handler := &ImportRecordBatchHandler{}
+	RegisterCommandHandler[*ImportRecord, int](&ImportRecordHandler{})
+	RegisterBatchCommandHandler[*ImportRecord, int](handler)
+	RegisterCommandPipelineBehavior(0, behavior)
+	commands := []*ImportRecord{{ID: 1}, {ID: 2}}
+
+	// act
+	result, err := SendBatch[*ImportRecord, int](context.TODO(), commands)
+
+	// assert
+	assert.Nil(t, err)
+	assert.Equal(t, []int{10, 20}, result.Responses())
+	assert.Len(t, handler.batches, 1)
+	assert.Equal(t, []interface{}{commands}, behavior.requests)
+}
+
+func TestSendBatch_WhenBatchHandlerAndValidationFails_ShouldOnlyHandleValidCommands(t *testing.T) {
+	// arrange
+	defer behaviors_cleanup(t)
+	defer batch_cleanup(t)
+	defer validation_cleanup(t)
+	handler := &ImportRecordBatchHandler{}
+	RegisterBatchCommandHandler[*ImportRecord, int](handler)
+	RegisterValidator[*ImportRecord](&ImportRecordValidator{})
+	RegisterCommandPipelineBehavior(0, NewValidationBehavior())
+	commands := []*ImportRecord{{ID: 1}, {ID: 0}, {ID: 3}}
+
+	// act
+	result, err := SendBatch[*ImportRecord, int](context.TODO(), commands)
+
+	// assert
+	var validationErr *ValidationError
+	assert.Nil(t, err)
+	assert.Equal(t, []int{10, 0, 30}, result.Responses())
+	assert.True(t, errors.As(result[1].Err, &validationErr))
+	assert.Equal(t, [][]*ImportRecord{{commands[0], commands[2]}}, handler.batches)
+}
+
+func TestSendBatch_WhenBatchHandlerAndPolicyForbids_ShouldNotCallHandler(t *testing.T) {
+	// arrange
+	defer behaviors_cleanup(t)
+	defer batch_cleanup(t)
+	defer authorization_cleanup(t)
+	handler := &ImportRecordBatchHandler{}
+	RegisterBatchCommandHandler[*ImportRecord, int](handler)
+	RegisterAuthorizationPolicy[*ImportRecord](&ImportRecordPolicy{})
+	RegisterCommandPipelineBehavior(0, NewAuthorizationBehavior())
+	ctx := WithPrincipal(context.TODO(), &FakePrincipal{ID: "1"})
+
+	// act
+	result, err := SendBatch[*ImportRecord, int](ctx, []*ImportRecord{{ID: 1}, {ID: 2}})
+
+	// assert
+	assert.Nil(t, err)
+	assert.Equal(t, 2, result.Failed())
+	assert.True(t, errors.Is(result[0].Err, ErrForbidden))
+	assert.Empty(t, handler.batches)
+}
+
+func TestSendBatch_WhenBatchHandlerAndCommandIsAudited_ShouldReturnErrBatchNotSupported(t *testing.T) {
+	// arrange
+	defer behaviors_cleanup(t)
+	defer batch_cleanup(t)
+	handler := &ImportRecordBatchHandler{}
+	behavior, _ := NewAuditBehavior(NewMemoryAuditSink(), AuditCommand[*ImportRecord]())
+	RegisterBatchCommandHandler[*ImportRecord, int](handler)
+	RegisterCommandPipelineBehavior(0, behavior)
+
+	// act
+	result, err := SendBatch[*ImportRecord, int](context.TODO(), []*ImportRecord{{ID: 1}})
+
+	// assert
+	assert.Nil(t, err)
+	assert.True(t, errors.Is(result[0].Err, ErrBatchNotSupported))
+	assert.Empty(t, handler.batches)
+}
+
+func TestSendBatch_WhenBehaviorCantCheckItems_ShouldSendEachCommand(t *testing.T) {
+	// arrange
+	defer commands_cleanup(t)
+	defer behaviors_cleanup(t)
+	defer batch_cleanup(t)
+	handler := &ImportRecordHandler{}
+	batchHandler := &ImportRecordBatchHandler{}
+	RegisterCommandHandler[*ImportRecord, int](handler)
+	RegisterBatchCommandHandler[*ImportRecord, int](batchHandler)
+	RegisterCommandPipelineBehavior(0, NewRateLimitBehavior(RateLimit{Rate: 1, Burst: 2}, LimitModeReject))
+
+	// act
+	result, err := SendBatch[*ImportRecord, int](context.TODO(), []*ImportRecord{{ID: 1}, {ID: 2}, {ID: 3}})
+
+	// assert
+	var limitErr *LimitError
+	assert.Nil(t, err)
+	assert.Equal(t, []int{1, 2, 0}, result.Responses())
+	assert.True(t, errors.As(result[2].Err, &limitErr))
+	assert.Equal(t, 2, handler.calls)
+	assert.Empty(t, batchHandler.batches)
+}
+
+func TestSendBatch_WhenBehaviorCantCheckItemsAndOnlyBatchHandler_ShouldReturnErrBatchNotSupported(t *testing.T) {
+	// arrange
+	defer behaviors_cleanup(t)
+	defer batch_cleanup(t)
+	handler := &ImportRecordBatchHandler{}
+	RegisterBatchCommandHandler[*ImportRecord, int](handler)
+	RegisterCommandPipelineBehavior(0, NewConcurrencyLimitBehavior(1, LimitModeReject))
+
+	// act
+	_, err := SendBatch[*ImportRecord, int](context.TODO(), []*ImportRecord{{ID: 1}})
+
+	// assert
+	assert.True(t, errors.Is(err, ErrBatchNotSupported))
+	assert.Empty(t, handler.batches)
+}
+
+func TestSendBatch_WhenBatchHandlerReturnsWrongCount_ShouldReturnError(t *testing.T) {
+	// arrange
+	defer batch_cleanup(t)
+	RegisterBatchCommandHandler[*ImportRecord, int](&ImportRecordBatchHandler{short: true})
+
+	// act
+	_, err := SendBatch[*ImportRecord, int](context.TODO(), []*ImportRecord{{ID: 1}})
+
+	// assert
+	assert.EqualError(t, err, "batch handler of type *cqrs.ImportRecordBatchHandler must return one result per command")
+}
+
+func TestSendBatch_WhenNoHandler_ShouldReturnError(t *testing.T) {
+	// act
+	_, err := SendBatch[*ImportRecord, int](context.TODO(), []*ImportRecord{{ID: 1}})
+
+	// assert
+	assert.EqualError(t, err, "no handler registered for command *cqrs.ImportRecord")
+}
+
+func TestRegisterBatchCommandHandler_WhenAlreadyRegistered_ShouldReturnError(t *testing.T) {
+	// arrange
+	defer batch_cleanup(t)
+	RegisterBatchCommandHandler[*ImportRecord, int](&ImportRecordBatchHandler{})
+
+	// act
+	err := RegisterBatchCommandHandler[*ImportRecord, int](&ImportRecordBatchHandler{})
+
+	// assert
+	assert.Error(t, err)
+}
@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"sort"
+
+	"github.com/ahmetb/go-linq/v3"
 )
 
 type NextFunc func() (interface{}, error)
@@ -13,15 +15,37 @@ type IBehavior interface {
 	Handle(ctx context.Context, request interface{}, next NextFunc) (interface{}, error)
 }
 
+type PipelineFunc func(ctx context.Context, request interface{}) (interface{}, error)
+
+type IPipelineBehavior interface {
+	Handle(ctx context.Context, request interface{}, next PipelineFunc) (interface{}, error)
+}
+
+type EventNextFunc func(ctx context.Context) error
+
+type IEventBehavior interface {
+	Handle(ctx context.Context, event interface{}, handler interface{}, next EventNextFunc) error
+}
+
 var commandBehaviors map[int]interface{}
 var queryBehaviors map[int]interface{}
+var eventBehaviors map[int]interface{}
 
 func init() {
 	commandBehaviors = make(map[int]interface{})
 	queryBehaviors = make(map[int]interface{})
+	eventBehaviors = make(map[int]interface{})
 }
 
 func RegisterCommandBehavior(order int, behavior IBehavior) error {
+	return registerCommandBehavior(order, behavior)
+}
+
+func RegisterCommandPipelineBehavior(order int, behavior IPipelineBehavior) error {
+	return registerCommandBehavior(order, behavior)
+}
+
+func registerCommandBehavior(order int, behavior interface{}) error {
 	_, found := commandBehaviors[order]
 
 	if found {
@@ -32,10 +56,17 @@ func RegisterCommandBehavior(order int, behavior IBehavior) error {
 	commandBehaviors[order] = behavior
 
 	return nil
-
 }
 
 func RegisterQueryBehavior(order int, behavior IBehavior) error {
+	return registerQueryBehavior(order, behavior)
+}
+
+func RegisterQueryPipelineBehavior(order int, behavior IPipelineBehavior) error {
+	return registerQueryBehavior(order, behavior)
+}
+
+func registerQueryBehavior(order int, behavior interface{}) error {
 	_, found := queryBehaviors[order]
 
 	if found {
@@ -48,6 +79,19 @@ func RegisterQueryBehavior(order int, behavior IBehavior) error {
 	return nil
 }
 
+func RegisterEventBehavior(order int, behavior IEventBehavior) error {
+	_, found := eventBehaviors[order]
+
+	if found {
+		msg := fmt.Sprintf("position %d is taken by another event behavior.", order)
+		return errors.New(msg)
+	}
+
+	eventBehaviors[order] = behavior
+
+	return nil
+}
+
 func sortBehaviors(behaviors map[int]interface{}) []interface{} {
 	keys := make([]int, 0)
 
@@ -65,3 +109,49 @@ func sortBehaviors(behaviors map[int]interface{}) []interface{} {
 
 	return sorted
 }
+
+type behaviorAdapter struct {
+	behavior IBehavior
+}
+
+func AdaptBehavior(behavior IBehavior) IPipelineBehavior {
+	return &behaviorAdapter{
+		behavior: behavior,
+	}
+}
+
+func (a *behaviorAdapter) Handle(ctx context.Context, request interface{}, next PipelineFunc) (interface{}, error) {
+	return a.behavior.Handle(ctx, request, func() (interface{}, error) {
+		return next(ctx, request)
+	})
+}
+
+func asPipelineBehavior(behavior interface{}) IPipelineBehavior {
+	pipelineBehavior, ok := behavior.(IPipelineBehavior)
+
+	if ok {
+		return pipelineBehavior
+	}
+
+	return AdaptBehavior(behavior.(IBehavior))
+}
+
+func runPipeline(ctx context.Context, request interface{}, behaviors map[int]interface{}, handle PipelineFunc) (interface{}, error) {
+	if len(behaviors) <= 0 {
+		return handle(ctx, request)
+	}
+
+	sortedBehaviors := sortBehaviors(behaviors)
+
+	aggregatedPipeline := linq.From(sortedBehaviors).AggregateWithSeedT(handle, func(next PipelineFunc, b interface{}) PipelineFunc {
+		behavior := asPipelineBehavior(b)
+		var nextFunc PipelineFunc = func(ctx context.Context, request interface{}) (interface{}, error) {
+			return behavior.Handle(ctx, request, next)
+		}
+		return nextFunc
+	})
+
+	pipeline := aggregatedPipeline.(PipelineFunc)
+
+	return pipeline(ctx, request)
+}
@@ -37,6 +37,7 @@ func (b *Behavior3) Handle(ctx context.Context, request interface{}, next NextFu
 func behaviors_cleanup(t *testing.T) {
 	commandBehaviors = make(map[int]interface{})
 	queryBehaviors = make(map[int]interface{})
+	eventBehaviors = make(map[int]interface{})
 }
 
 func TestRegisterCommandBehavior_WhenPositionIsNotTaken_ShouldRegisterBehavior(t *testing.T) {
@@ -91,6 +92,135 @@ func TestRegisterQueryBehavior_WhenPositionIsTaken_ShouldReturnError(t *testing.
 	assert.Error(t, err)
 }
 
+type tenantContextKey struct{}
+
+type TenantBehavior struct {
+}
+
+func (b *TenantBehavior) Handle(ctx context.Context, request interface{}, next PipelineFunc) (interface{}, error) {
+	ctx = context.WithValue(ctx, tenantContextKey{}, "tenant")
+	return next(ctx, request)
+}
+
+type ReplacingBehavior struct {
+	request interface{}
+}
+
+func (b *ReplacingBehavior) Handle(ctx context.Context, request interface{}, next PipelineFunc) (interface{}, error) {
+	return next(ctx, b.request)
+}
+
+func TestRegisterCommandPipelineBehavior_WhenPositionIsNotTaken_ShouldRegisterBehavior(t *testing.T) {
+	// arrange
+	defer behaviors_cleanup(t)
+	behavior := &TenantBehavior{}
+
+	// act
+	err := RegisterCommandPipelineBehavior(0, behavior)
+
+	// assert
+	assert.Nil(t, err)
+	assert.Equal(t, commandBehaviors[0], behavior)
+}
+
+func TestRegisterCommandPipelineBehavior_WhenPositionIsTakenByBehavior_ShouldReturnError(t *testing.T) {
+	// arrange
+	defer behaviors_cleanup(t)
+	RegisterCommandBehavior(0, &Behavior1{})
+
+	// act
+	err := RegisterCommandPipelineBehavior(0, &TenantBehavior{})
+
+	// assert
+	assert.Error(t, err)
+}
+
+func TestRegisterQueryPipelineBehavior_WhenPositionIsTaken_ShouldReturnError(t *testing.T) {
+	// arrange
+	defer behaviors_cleanup(t)
+	RegisterQueryPipelineBehavior(0, &TenantBehavior{})
+
+	// act
+	err := RegisterQueryPipelineBehavior(0, &TenantBehavior{})
+
+	// assert
+	assert.Error(t, err)
+}
+
+func TestAdaptBehavior_GivenBehavior_ShouldForwardContextAndRequest(t *testing.T) {
+	// arrange
+	behavior := AdaptBehavior(&Behavior1{})
+	ctx := context.WithValue(context.TODO(), tenantContextKey{}, "tenant")
+	command := &Command1{}
+	var receivedCtx context.Context
+	var receivedRequest interface{}
+	next := func(ctx context.Context, request interface{}) (interface{}, error) {
+		receivedCtx = ctx
+		receivedRequest = request
+		return nil, nil
+	}
+
+	// act
+	behavior.Handle(ctx, command, next)
+
+	// assert
+	assert.Equal(t, ctx, receivedCtx)
+	assert.Same(t, command, receivedRequest)
+}
+
+func TestRunPipeline_WhenMixingBehaviors_ShouldPropagateContextToHandler(t *testing.T) {
+	// arrange
+	behaviorsMap := map[int]interface{}{
+		0: &TenantBehavior{},
+		1: &Behavior1{},
+	}
+	var tenant interface{}
+	handle := func(ctx context.Context, request interface{}) (interface{}, error) {
+		tenant = ctx.Value(tenantContextKey{})
+		return nil, nil
+	}
+
+	// act
+	runPipeline(context.TODO(), &Command1{}, behaviorsMap, handle)
+
+	// assert
+	assert.Equal(t, "tenant", tenant)
+}
+
+type EventBehavior1 struct {
+}
+
+func (b *EventBehavior1) Handle(ctx context.Context, event interface{}, handler interface{}, next EventNextFunc) error {
+	return next(ctx)
+}
+
+func TestRegisterEventBehavior_WhenPositionIsNotTaken_ShouldRegisterBehavior(t *testing.T) {
+	// arrange
+	defer behaviors_cleanup(t)
+	behavior := &EventBehavior1{}
+
+	// act
+	err := RegisterEventBehavior(0, behavior)
+
+	// assert
+	assert.Nil(t, err)
+	assert.Equal(t, eventBehaviors[0], behavior)
+}
+
+func TestRegisterEventBehavior_WhenPositionIsTaken_ShouldReturnError(t *testing.T) {
+	// arrange
+	defer behaviors_cleanup(t)
+	behavior1 := &EventBehavior1{}
+	behavior2 := &EventBehavior1{}
+
+	// act
+	RegisterEventBehavior(0, behavior1)
+	err := RegisterEventBehavior(0, behavior2)
+
+	// assert
+	assert.Error(t, err)
+}
+
 func TestSortBehaviors_GivenBehaviorMap_ShouldSortBehaviors(t *testing.T) {
 	// arrange
 	behaviorsMap := map[int]interface{}{
@@ -0,0 +1,268 @@
+package cqrs
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
+
+type Cacheable interface {
+	CacheKey() string
+	CacheTTL() time.Duration
+}
+
+type QueryCache interface {
+	Get(ctx context.Context, key string) (interface{}, bool, error)
+	Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+}
+
+type CacheErrorHook func(ctx context.Context, key string, err error)
+
+type CachingOption func(behavior *CachingBehavior)
+
+func OnCacheError(hook CacheErrorHook) CachingOption {
+	return func(behavior *CachingBehavior) {
+		behavior.onError = hook
+	}
+}
+
+type CachingBehavior struct {
+	cache   QueryCache
+	flights *flightGroup
+	onError CacheErrorHook
+}
+
+func NewCachingBehavior(cache QueryCache, options ...CachingOption) *CachingBehavior {
+	behavior := &CachingBehavior{
+		cache:   cache,
+		flights: newFlightGroup(),
+	}
+
+	for _, option := range options {
+		option(behavior)
+	}
+
+	return behavior
+}
+
+func (b *CachingBehavior) Handle(ctx context.Context, request interface{}, next PipelineFunc) (interface{}, error) {
+	cacheable, ok := request.(Cacheable)
+
+	if !ok || cacheable.CacheKey() == "" {
+		return next(ctx, request)
+	}
+
+	key := cacheKey(request, cacheable.CacheKey())
+
+	cached, found, err := b.cache.Get(ctx, key)
+
+	// an unavailable cache is treated as a miss
+	if err != nil {
+		b.reportError(ctx, key, err)
+	}
+
+	if err == nil && found {
+		if fitsResponse(ctx, cached) {
+			return cached, nil
+		}
+
+		msg := fmt.Sprintf("cached value of type %T doesn't match the query response", cached)
+		b.reportError(ctx, key, errors.New(msg))
+	}
+
+	return b.flights.do(ctx, key, func() (interface{}, error) {
+		res, err := next(ctx, request)
+
+		if err != nil {
+			return res, err
+		}
+
+		if err := b.cache.Set(ctx, key, res, cacheable.CacheTTL()); err != nil {
+			b.reportError(ctx, key, err)
+		}
+
+		return res, nil
+	})
+}
+
+// keys are namespaced by query type, so two queries returning the same key
+// never share an entry
+func cacheKey(request interface{}, key string) string {
+	return fmt.Sprintf("%T:%s", request, key)
+}
+
+func fitsResponse(ctx context.Context, cached interface{}) bool {
+	info, _ := DispatchInfoFromContext(ctx)
+
+	if info.responseType == nil {
+		return true
+	}
+
+	if cached == nil {
+		switch info.responseType.Kind() {
+		case reflect.Pointer, reflect.Interface, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan:
+			return true
+		}
+
+		return false
+	}
+
+	return reflect.TypeOf(cached).AssignableTo(info.responseType)
+}
+
+func (b *CachingBehavior) reportError(ctx context.Context, key string, err error) {
+	if b.onError != nil {
+		b.onError(ctx, key, err)
+	}
+}
+
+type cacheInvalidationHandler[TEvent any, TQuery any] struct {
+	cache QueryCache
+	keys  func(event TEvent) []string
+}
+
+func (h *cacheInvalidationHandler[TEvent, TQuery]) Handle(ctx context.Context, event TEvent) error {
+	keys := h.keys(event)
+
+	if len(keys) <= 0 {
+		return nil
+	}
+
+	var query TQuery
+
+	for i, key := range keys {
+		keys[i] = cacheKey(query, key)
+	}
+
+	return h.cache.Delete(ctx, keys...)
+}
+
+func RegisterCacheInvalidation[TEvent any, TQuery any](cache QueryCache, keys func(event TEvent) []string) error {
+	if cache == nil {
+		return errors.New("a query cache must be provided")
+	}
+
+	if keys == nil {
+		return errors.New("a cache keys function must be provided")
+	}
+
+	handler := &cacheInvalidationHandler[TEvent, TQuery]{
+		cache: cache,
+		keys:  keys,
+	}
+
+	return RegisterEventSubscriber[TEvent](handler)
+}
+
+type lruEntry struct {
+	key       string
+	value     interface{}
+	expiresAt time.Time
+}
+
+type LRUQueryCache struct {
+	mutex    sync.Mutex
+	capacity int
+	entries  map[string]*list.Element
+	order    *list.List
+	now      func() time.Time
+}
+
+func NewLRUQueryCache(capacity int) (*LRUQueryCache, error) {
+	if capacity <= 0 {
+		return nil, errors.New("the cache capacity must be greater than zero")
+	}
+
+	cache := &LRUQueryCache{
+		capacity: capacity,
+		entries:  make(map[string]*list.Element),
+		order:    list.New(),
+		now:      time.Now,
+	}
+
+	return cache, nil
+}
+
+func (c *LRUQueryCache) Get(ctx context.Context, key string) (interface{}, bool, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	element, found := c.entries[key]
+
+	if !found {
+		return nil, false, nil
+	}
+
+	entry := element.Value.(*lruEntry)
+
+	if !entry.expiresAt.IsZero() && !c.now().Before(entry.expiresAt) {
+		c.remove(element)
+		return nil, false, nil
+	}
+
+	c.order.MoveToFront(element)
+
+	return entry.value, true, nil
+}
+
+func (c *LRUQueryCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	entry := &lruEntry{
+		key:   key,
+		value: value,
+	}
+
+	if ttl > 0 {
+		entry.expiresAt = c.now().Add(ttl)
+	}
+
+	element, found := c.entries[key]
+
+	if found {
+		element.Value = entry
+		c.order.MoveToFront(element)
+		return nil
+	}
+
+	c.entries[key] = c.order.PushFront(entry)
+
+	if c.order.Len() > c.capacity {
+		c.remove(c.order.Back())
+	}
+
+	return nil
+}
+
+func (c *LRUQueryCache) Delete(ctx context.Context, keys ...string) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for _, key := range keys {
+		element, found := c.entries[key]
+
+		if found {
+			c.remove(element)
+		}
+	}
+
+	return nil
+}
+
+func (c *LRUQueryCache) Len() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.order.Len()
+}
+
+func (c *LRUQueryCache) remove(element *list.Element) {
+	c.order.Remove(element)
+	delete(c.entries, element.Value.(*lruEntry).key)
+}
@@ -0,0 +1,287 @@
+package cqrs
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type CacheableQuery struct {
+	ID string
+}
+
+func (q *CacheableQuery) CacheKey() string {
+	return "product:" + q.ID
+}
+
+func (q *CacheableQuery) CacheTTL() time.Duration {
+	return time.Minute
+}
+
+type CacheableQueryHandler struct {
+	calls int32
+	delay time.Duration
+}
+
+func (h *CacheableQueryHandler) Handle(ctx context.Context, query *CacheableQuery) (*Response, error) {
+	atomic.AddInt32(&h.calls, 1)
+	time.Sleep(h.delay)
+	return &Response{}, nil
+}
+
+type ProductUpdated struct {
+	ID string
+}
+
+func newLRUQueryCache(t *testing.T, capacity int) *LRUQueryCache {
+	cache, err := NewLRUQueryCache(capacity)
+	assert.Nil(t, err)
+	return cache
+}
+
+func TestCachingBehavior_WhenQueryIsCached_ShouldNotCallHandler(t *testing.T) {
+	// arrange
+	defer querys_cleanup(t)
+	defer behaviors_cleanup(t)
+	handler := &CacheableQueryHandler{}
+	RegisterQueryHandler[*CacheableQuery, *Response](handler)
+	RegisterQueryPipelineBehavior(0, NewCachingBehavior(newLRUQueryCache(t, 10)))
+
+	// act
+	first, _ := Request[*CacheableQuery, *Response](context.TODO(), &CacheableQuery{ID: "1"})
+	second, err := Request[*CacheableQuery, *Response](context.TODO(), &CacheableQuery{ID: "1"})
+
+	// assert
+	assert.Nil(t, err)
+	assert.Same(t, first, second)
+	assert.Equal(t, int32(1), handler.calls)
+}
+
+func TestCachingBehavior_WhenQueryIsNotCacheable_ShouldCallNext(t *testing.T) {
+	// arrange
+	behavior := NewCachingBehavior(newLRUQueryCache(t, 10))
+	calls := 0
+	next := func(ctx context.Context, request interface{}) (interface{}, error) {
+		calls++
+		return calls, nil
+	}
+
+	// act
+	behavior.Handle(context.TODO(), &Query1{}, next)
+	res, _ := behavior.Handle(context.TODO(), &Query1{}, next)
+
+	// assert
+	assert.Equal(t, 2, res)
+}
+
+func TestCachingBehavior_WhenHandlerFails_ShouldNotCacheResponse(t *testing.T) {
+	// arrange
+	cache := newLRUQueryCache(t, 10)
+	behavior := NewCachingBehavior(cache)
+	next := func(ctx context.Context, request interface{}) (interface{}, error) {
+		return nil, errors.New("failed")
+	}
+
+	// act
+	_, err := behavior.Handle(context.TODO(), &CacheableQuery{ID: "1"}, next)
+
+	// assert
+	assert.Error(t, err)
+	assert.Equal(t, 0, cache.Len())
+}
+
+func TestCachingBehavior_WhenConcurrentIdenticalQueries_ShouldCallHandlerOnce(t *testing.T) {
+	// arrange
+	behavior := NewCachingBehavior(newLRUQueryCache(t, 10))
+	handler := &CacheableQueryHandler{delay: 20 * time.Millisecond}
+	query := &CacheableQuery{ID: "1"}
+	next := func(ctx context.Context, request interface{}) (interface{}, error) {
+		return handler.Handle(context.TODO(), query)
+	}
+	var wg sync.WaitGroup
+
+	// act
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			behavior.Handle(context.TODO(), query, next)
+		}()
+	}
+	wg.Wait()
+
+	// assert
+	assert.Equal(t, int32(1), handler.calls)
+}
+
+func TestCachingBehavior_WhenCacheIsUnavailable_ShouldCallHandlerAndReportErrors(t *testing.T) {
+	// arrange
+	defer querys_cleanup(t)
+	defer behaviors_cleanup(t)
+	handler := &CacheableQueryHandler{}
+	reported := []error{}
+	cache := &unavailableQueryCache{err: errors.New("connection refused")}
+	RegisterQueryHandler[*CacheableQuery, *Response](handler)
+	RegisterQueryPipelineBehavior(0, NewCachingBehavior(cache, OnCacheError(func(ctx context.Context, key string, err error) {
+		reported = append(reported, err)
+	})))
+
+	// act
+	res, err := Request[*CacheableQuery, *Response](context.TODO(), &CacheableQuery{ID: "1"})
+
+	// assert
+	assert.Nil(t, err)
+	assert.NotNil(t, res)
+	assert.Equal(t, int32(1), handler.calls)
+	assert.Len(t, reported, 2)
+}
+
+type CacheableCountQuery struct {
+	ID string
+}
+
+func (q *CacheableCountQuery) CacheKey() string {
+	return "product:" + q.ID
+}
+
+func (q *CacheableCountQuery) CacheTTL() time.Duration {
+	return time.Minute
+}
+
+type CacheableCountQueryHandler struct {
+}
+
+func (h *CacheableCountQueryHandler) Handle(ctx context.Context, query *CacheableCountQuery) (int, error) {
+	return 3, nil
+}
+
+func TestCachingBehavior_WhenQueryTypesShareKey_ShouldNotShareEntries(t *testing.T) {
+	// arrange
+	defer querys_cleanup(t)
+	defer behaviors_cleanup(t)
+	cache := newLRUQueryCache(t, 10)
+	RegisterQueryHandler[*CacheableQuery, *Response](&CacheableQueryHandler{})
+	RegisterQueryHandler[*CacheableCountQuery, int](&CacheableCountQueryHandler{})
+	RegisterQueryPipelineBehavior(0, NewCachingBehavior(cache))
+
+	// act
+	Request[*CacheableQuery, *Response](context.TODO(), &CacheableQuery{ID: "1"})
+	count, err := Request[*CacheableCountQuery, int](context.TODO(), &CacheableCountQuery{ID: "1"})
+
+	// assert
+	assert.Nil(t, err)
+	assert.Equal(t, 3, count)
+	assert.Equal(t, 2, cache.Len())
+}
+
+func TestCachingBehavior_WhenCachedValueHasOtherType_ShouldTreatAsMiss(t *testing.T) {
+	// arrange
+	defer querys_cleanup(t)
+	defer behaviors_cleanup(t)
+	cache := newLRUQueryCache(t, 10)
+	cache.Set(context.TODO(), "*cqrs.CacheableCountQuery:product:1", "stale", time.Minute)
+	reported := []error{}
+	RegisterQueryHandler[*CacheableCountQuery, int](&CacheableCountQueryHandler{})
+	RegisterQueryPipelineBehavior(0, NewCachingBehavior(cache, OnCacheError(func(ctx context.Context, key string, err error) {
+		reported = append(reported, err)
+	})))
+
+	// act
+	count, err := Request[*CacheableCountQuery, int](context.TODO(), &CacheableCountQuery{ID: "1"})
+
+	// assert
+	cached, _, _ := cache.Get(context.TODO(), "*cqrs.CacheableCountQuery:product:1")
+	assert.Nil(t, err)
+	assert.Equal(t, 3, count)
+	assert.Equal(t, 3, cached)
+	assert.Len(t, reported, 1)
+}
+
+func TestRegisterCacheInvalidation_WhenEventPublished_ShouldDeleteKeys(t *testing.T) {
+	// arrange
+	defer events_cleanup(t)
+	cache := newLRUQueryCache(t, 10)
+	cache.Set(context.TODO(), "*cqrs.CacheableQuery:product:1", &Response{}, time.Minute)
+	cache.Set(context.TODO(), "*cqrs.CacheableQuery:product:2", &Response{}, time.Minute)
+	RegisterCacheInvalidation[*ProductUpdated, *CacheableQuery](cache, func(event *ProductUpdated) []string {
+		return []string{"product:" + event.ID}
+	})
+
+	// act
+	err := PublishEvent(context.TODO(), &ProductUpdated{ID: "1"})
+	_, invalidated, _ := cache.Get(context.TODO(), "*cqrs.CacheableQuery:product:1")
+	_, kept, _ := cache.Get(context.TODO(), "*cqrs.CacheableQuery:product:2")
+
+	// assert
+	assert.Nil(t, err)
+	assert.False(t, invalidated)
+	assert.True(t, kept)
+}
+
+func TestNewLRUQueryCache_WhenCapacityIsNotPositive_ShouldReturnError(t *testing.T) {
+	// act
+	_, err := NewLRUQueryCache(0)
+
+	// assert
+	assert.Error(t, err)
+}
+
+func TestLRUQueryCache_WhenCapacityExceeded_ShouldEvictLeastRecentlyUsed(t *testing.T) {
+	// arrange
+	cache := newLRUQueryCache(t, 2)
+	cache.Set(context.TODO(), "a", 1, 0)
+	cache.Set(context.TODO(), "b", 2, 0)
+	cache.Get(context.TODO(), "a")
+
+	// act
+	cache.Set(context.TODO(), "c", 3, 0)
+	_, foundA, _ := cache.Get(context.TODO(), "a")
+	_, foundB, _ := cache.Get(context.TODO(), "b")
+	_, foundC, _ := cache.Get(context.TODO(), "c")
+
+	// assert
+	assert.True(t, foundA)
+	assert.False(t, foundB)
+	assert.True(t, foundC)
+}
+
+func TestLRUQueryCache_WhenEntryExpired_ShouldNotReturnEntry(t *testing.T) {
+	// arrange
+	cache := newLRUQueryCache(t, 2)
+	now := time.Now()
+	cache.now = func() time.Time {
+		return now
+	}
+	cache.Set(context.TODO(), "a", 1, time.Second)
+	cache.now = func() time.Time {
+		return now.Add(time.Second)
+	}
+
+	// act
+	_, found, _ := cache.Get(context.TODO(), "a")
+
+	// assert
+	assert.False(t, found)
+	assert.Equal(t, 0, cache.Len())
+}
+
+type unavailableQueryCache struct {
+	err error
+}
+
+func (c *unavailableQueryCache) Get(ctx context.Context, key string) (interface{}, bool, error) {
+	return nil, false, c.err
+}
+
+func (c *unavailableQueryCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	return c.err
+}
+
+func (c *unavailableQueryCache) Delete(ctx context.Context, keys ...string) error {
+	return c.err
+}
@@ -0,0 +1,324 @@
+package cqrs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
+
+type CircuitState int
+
+const (
+	CircuitClosed CircuitState = iota
+	CircuitOpen
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return fmt.Sprintf("CircuitState(%d)", int(s))
+	}
+}
+
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+type CircuitOpenError struct {
+	MessageIDs
+	Key string
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("circuit breaker for %s is open", e.Key)
+}
+
+func (e *CircuitOpenError) Unwrap() error {
+	return ErrCircuitOpen
+}
+
+type CircuitKeyer interface {
+	CircuitKey() string
+}
+
+type CircuitBreakerSettings struct {
+	FailureThreshold    int
+	Cooldown            time.Duration
+	HalfOpenMaxRequests int
+	IsFailure           func(err error) bool
+}
+
+type CircuitStateChangeHook func(key string, from CircuitState, to CircuitState)
+
+type CircuitBreakerOption func(behavior *CircuitBreakerBehavior)
+
+func CircuitBreakerFor[TRequest any](settings CircuitBreakerSettings) CircuitBreakerOption {
+	var request TRequest
+	requestType := reflect.TypeOf(request)
+
+	return func(behavior *CircuitBreakerBehavior) {
+		behavior.settingsByType[requestType] = settings.withDefaults()
+	}
+}
+
+func OnCircuitStateChange(hook CircuitStateChangeHook) CircuitBreakerOption {
+	return func(behavior *CircuitBreakerBehavior) {
+		behavior.onStateChange = hook
+	}
+}
+
+type circuit struct {
+	settings   CircuitBreakerSettings
+	state      CircuitState
+	generation int
+	failures   int
+	successes  int
+	inFlight   int
+	active     int
+	openedAt   time.Time
+}
+
+type circuitTransition struct {
+	from CircuitState
+	to   CircuitState
+}
+
+type CircuitBreakerBehavior struct {
+	settings       CircuitBreakerSettings
+	settingsByType map[reflect.Type]CircuitBreakerSettings
+	onStateChange  CircuitStateChangeHook
+	mutex          sync.Mutex
+	circuits       map[string]*circuit
+	now            func() time.Time
+}
+
+func NewCircuitBreakerBehavior(settings CircuitBreakerSettings, options ...CircuitBreakerOption) *CircuitBreakerBehavior {
+	behavior := &CircuitBreakerBehavior{
+		settings:       settings.withDefaults(),
+		settingsByType: make(map[reflect.Type]CircuitBreakerSettings),
+		circuits:       make(map[string]*circuit),
+		now:            time.Now,
+	}
+
+	for _, option := range options {
+		option(behavior)
+	}
+
+	return behavior
+}
+
+func (b *CircuitBreakerBehavior) Handle(ctx context.Context, request interface{}, next PipelineFunc) (res interface{}, err error) {
+	key := circuitKey(request)
+
+	generation, err := b.allow(ctx, key, request)
+
+	if err != nil {
+		return nil, err
+	}
+
+	panicked := true
+
+	defer func() {
+		b.record(key, generation, err, panicked)
+	}()
+
+	res, err = next(ctx, request)
+	panicked = false
+
+	return res, err
+}
+
+func (b *CircuitBreakerBehavior) State(key string) CircuitState {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	c, found := b.circuits[key]
+
+	if !found {
+		return CircuitClosed
+	}
+
+	return c.state
+}
+
+func (b *CircuitBreakerBehavior) allow(ctx context.Context, key string, request interface{}) (int, error) {
+	b.mutex.Lock()
+
+	c := b.circuit(key, request)
+	transitions := make([]circuitTransition, 0)
+
+	if c.state == CircuitOpen && !b.now().Before(c.openedAt.Add(c.settings.Cooldown)) {
+		transitions = append(transitions, c.transition(CircuitHalfOpen, b.now()))
+	}
+
+	allowed := c.state == CircuitClosed || (c.state == CircuitHalfOpen && c.inFlight < c.settings.HalfOpenMaxRequests)
+
+	if allowed && c.state == CircuitHalfOpen {
+		c.inFlight++
+	}
+
+	if allowed {
+		c.active++
+	}
+
+	generation := c.generation
+
+	b.mutex.Unlock()
+
+	b.notify(key, transitions)
+
+	if !allowed {
+		return generation, &CircuitOpenError{MessageIDs: MessageIDsFromContext(ctx), Key: key}
+	}
+
+	return generation, nil
+}
+
+func (b *CircuitBreakerBehavior) record(key string, generation int, err error, panicked bool) {
+	b.mutex.Lock()
+
+	c := b.circuits[key]
+	c.active--
+
+	// results of requests admitted before the last transition no longer apply
+	if c.generation != generation {
+		b.evict(key, c)
+		b.mutex.Unlock()
+		return
+	}
+
+	failed := panicked || (err != nil && c.settings.IsFailure(err))
+	transitions := make([]circuitTransition, 0)
+
+	switch c.state {
+	case CircuitClosed:
+		if !failed {
+			c.failures = 0
+			break
+		}
+
+		c.failures++
+
+		if c.failures >= c.settings.FailureThreshold {
+			transitions = append(transitions, c.transition(CircuitOpen, b.now()))
+		}
+	case CircuitHalfOpen:
+		c.inFlight--
+
+		if failed {
+			transitions = append(transitions, c.transition(CircuitOpen, b.now()))
+			break
+		}
+
+		c.successes++
+
+		if c.successes >= c.settings.HalfOpenMaxRequests {
+			transitions = append(transitions, c.transition(CircuitClosed, b.now()))
+		}
+	}
+
+	b.evict(key, c)
+	b.mutex.Unlock()
+
+	b.notify(key, transitions)
+}
+
+// a closed circuit without failures is the same as a new one, so idle ones are dropped
+func (b *CircuitBreakerBehavior) evict(key string, c *circuit) {
+	if c.state == CircuitClosed && c.failures == 0 && c.active == 0 {
+		delete(b.circuits, key)
+	}
+}
+
+func (b *CircuitBreakerBehavior) circuit(key string, request interface{}) *circuit {
+	c, found := b.circuits[key]
+
+	if found {
+		return c
+	}
+
+	settings, found := b.settingsByType[reflect.TypeOf(request)]
+
+	if !found {
+		settings = b.settings
+	}
+
+	c = &circuit{
+		settings: settings,
+		state:    CircuitClosed,
+	}
+	b.circuits[key] = c
+
+	return c
+}
+
+func (b *CircuitBreakerBehavior) notify(key string, transitions []circuitTransition) {
+	if b.onStateChange == nil {
+		return
+	}
+
+	for _, transition := range transitions {
+		b.onStateChange(key, transition.from, transition.to)
+	}
+}
+
+func (c *circuit) transition(to CircuitState, now time.Time) circuitTransition {
+	transition := circuitTransition{
+		from: c.state,
+		to:   to,
+	}
+
+	c.state = to
+	c.generation++
+	c.failures = 0
+	c.successes = 0
+	c.inFlight = 0
+
+	if to == CircuitOpen {
+		c.openedAt = now
+	}
+
+	return transition
+}
+
+func circuitKey(request interface{}) string {
+	keyer, ok := request.(CircuitKeyer)
+
+	if ok && keyer.CircuitKey() != "" {
+		return keyer.CircuitKey()
+	}
+
+	return reflect.TypeOf(request).String()
+}
+
+func (s CircuitBreakerSettings) withDefaults() CircuitBreakerSettings {
+	if s.FailureThreshold <= 0 {
+		s.FailureThreshold = 5
+	}
+
+	if s.Cooldown <= 0 {
+		s.Cooldown = 30 * time.Second
+	}
+
+	if s.HalfOpenMaxRequests <= 0 {
+		s.HalfOpenMaxRequests = 1
+	}
+
+	if s.IsFailure == nil {
+		s.IsFailure = isCircuitFailure
+	}
+
+	return s
+}
+
+func isCircuitFailure(err error) bool {
+	return !errors.Is(err, context.Canceled)
+}
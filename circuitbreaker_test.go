@@ -0,0 +1,263 @@
+package cqrs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type TenantCommand struct {
+	Tenant string
+}
+
+func (c *TenantCommand) CircuitKey() string {
+	return "tenant:" + c.Tenant
+}
+
+func failingNext(ctx context.Context, request interface{}) (interface{}, error) {
+	return nil, errors.New("dependency unavailable")
+}
+
+func succeedingNext(ctx context.Context, request interface{}) (interface{}, error) {
+	return &Response{}, nil
+}
+
+func TestCircuitBreakerBehavior_WhenFailureThresholdReached_ShouldOpenCircuit(t *testing.T) {
+	// arrange
+	behavior := NewCircuitBreakerBehavior(CircuitBreakerSettings{FailureThreshold: 2, Cooldown: time.Minute})
+	calls := 0
+	next := func(ctx context.Context, request interface{}) (interface{}, error) {
+		calls++
+		return failingNext(ctx, request)
+	}
+
+	// act
+	behavior.Handle(context.TODO(), &Command1{}, next)
+	behavior.Handle(context.TODO(), &Command1{}, next)
+	_, err := behavior.Handle(context.TODO(), &Command1{}, next)
+
+	// assert
+	var openErr *CircuitOpenError
+	assert.True(t, errors.Is(err, ErrCircuitOpen))
+	assert.True(t, errors.As(err, &openErr))
+	assert.Equal(t, "*cqrs.Command1", openErr.Key)
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, CircuitOpen, behavior.State("*cqrs.Command1"))
+}
+
+func TestCircuitBreakerBehavior_WhenSuccessBetweenFailures_ShouldKeepCircuitClosed(t *testing.T) {
+	// arrange
+	behavior := NewCircuitBreakerBehavior(CircuitBreakerSettings{FailureThreshold: 2})
+
+	// act
+	behavior.Handle(context.TODO(), &Command1{}, failingNext)
+	behavior.Handle(context.TODO(), &Command1{}, succeedingNext)
+	behavior.Handle(context.TODO(), &Command1{}, failingNext)
+
+	// assert
+	assert.Equal(t, CircuitClosed, behavior.State("*cqrs.Command1"))
+}
+
+func TestCircuitBreakerBehavior_WhenCooldownElapsedAndTrialSucceeds_ShouldCloseCircuit(t *testing.T) {
+	// arrange
+	var transitions []CircuitState
+	behavior := NewCircuitBreakerBehavior(
+		CircuitBreakerSettings{FailureThreshold: 1, Cooldown: time.Second},
+		OnCircuitStateChange(func(key string, from CircuitState, to CircuitState) {
+			transitions = append(transitions, to)
+		}),
+	)
+	now := time.Now()
+	behavior.now = func() time.Time {
+		return now
+	}
+	behavior.Handle(context.TODO(), &Command1{}, failingNext)
+	behavior.now = func() time.Time {
+		return now.Add(time.Second)
+	}
+
+	// act
+	res, err := behavior.Handle(context.TODO(), &Command1{}, succeedingNext)
+
+	// assert
+	assert.Nil(t, err)
+	assert.Equal(t, &Response{}, res)
+	assert.Equal(t, []CircuitState{CircuitOpen, CircuitHalfOpen, CircuitClosed}, transitions)
+}
+
+func TestCircuitBreakerBehavior_WhenTrialFails_ShouldReopenCircuit(t *testing.T) {
+	// arrange
+	behavior := NewCircuitBreakerBehavior(CircuitBreakerSettings{FailureThreshold: 1, Cooldown: time.Second})
+	now := time.Now()
+	behavior.now = func() time.Time {
+		return now
+	}
+	behavior.Handle(context.TODO(), &Command1{}, failingNext)
+	behavior.now = func() time.Time {
+		return now.Add(time.Second)
+	}
+
+	// act
+	behavior.Handle(context.TODO(), &Command1{}, failingNext)
+	_, err := behavior.Handle(context.TODO(), &Command1{}, succeedingNext)
+
+	// assert
+	assert.True(t, errors.Is(err, ErrCircuitOpen))
+	assert.Equal(t, CircuitOpen, behavior.State("*cqrs.Command1"))
+}
+
+func TestCircuitBreakerBehavior_WhenTrialPanics_ShouldReopenCircuit(t *testing.T) {
+	// arrange
+	behavior := NewCircuitBreakerBehavior(CircuitBreakerSettings{FailureThreshold: 1, Cooldown: time.Second})
+	now := time.Now()
+	behavior.now = func() time.Time {
+		return now
+	}
+	behavior.Handle(context.TODO(), &Command1{}, failingNext)
+	behavior.now = func() time.Time {
+		return now.Add(time.Second)
+	}
+	panickingNext := func(ctx context.Context, request interface{}) (interface{}, error) {
+		panic("boom")
+	}
+
+	// act
+	handle := func() {
+		behavior.Handle(context.TODO(), &Command1{}, panickingNext)
+	}
+
+	// assert
+	assert.PanicsWithValue(t, "boom", handle)
+	assert.Equal(t, CircuitOpen, behavior.State("*cqrs.Command1"))
+	behavior.now = func() time.Time {
+		return now.Add(2 * time.Second)
+	}
+	_, err := behavior.Handle(context.TODO(), &Command1{}, succeedingNext)
+	assert.Nil(t, err)
+	assert.Equal(t, CircuitClosed, behavior.State("*cqrs.Command1"))
+}
+
+func TestCircuitBreakerBehavior_WhenResultArrivesAfterTransition_ShouldIgnoreIt(t *testing.T) {
+	// arrange
+	behavior := NewCircuitBreakerBehavior(CircuitBreakerSettings{FailureThreshold: 1, Cooldown: time.Second, HalfOpenMaxRequests: 1})
+	now := time.Now()
+	behavior.now = func() time.Time {
+		return now
+	}
+	started, release := make(chan struct{}), make(chan struct{})
+	slowNext := func(ctx context.Context, request interface{}) (interface{}, error) {
+		close(started)
+		<-release
+		return succeedingNext(ctx, request)
+	}
+	done := make(chan struct{})
+
+	go func() {
+		behavior.Handle(context.TODO(), &Command1{}, slowNext)
+		close(done)
+	}()
+
+	<-started
+	behavior.Handle(context.TODO(), &Command1{}, failingNext)
+	behavior.now = func() time.Time {
+		return now.Add(time.Second)
+	}
+	trialStarted, trialRelease := make(chan struct{}), make(chan struct{})
+	trialNext := func(ctx context.Context, request interface{}) (interface{}, error) {
+		close(trialStarted)
+		<-trialRelease
+		return succeedingNext(ctx, request)
+	}
+	trialDone := make(chan struct{})
+
+	go func() {
+		behavior.Handle(context.TODO(), &Command1{}, trialNext)
+		close(trialDone)
+	}()
+
+	<-trialStarted
+
+	// act
+	close(release)
+	<-done
+	_, err := behavior.Handle(context.TODO(), &Command1{}, succeedingNext)
+	close(trialRelease)
+	<-trialDone
+
+	// assert
+	assert.True(t, errors.Is(err, ErrCircuitOpen))
+	assert.Equal(t, CircuitClosed, behavior.State("*cqrs.Command1"))
+}
+
+func TestCircuitBreakerBehavior_WhenRequestSuppliesKey_ShouldTrackCircuitPerKey(t *testing.T) {
+	// arrange
+	behavior := NewCircuitBreakerBehavior(CircuitBreakerSettings{FailureThreshold: 1})
+
+	// act
+	behavior.Handle(context.TODO(), &TenantCommand{Tenant: "a"}, failingNext)
+	_, err := behavior.Handle(context.TODO(), &TenantCommand{Tenant: "b"}, succeedingNext)
+
+	// assert
+	assert.Nil(t, err)
+	assert.Equal(t, CircuitOpen, behavior.State("tenant:a"))
+	assert.Equal(t, CircuitClosed, behavior.State("tenant:b"))
+}
+
+func TestCircuitBreakerBehavior_WhenSettingsForTypeRegistered_ShouldUseTypeSettings(t *testing.T) {
+	// arrange
+	behavior := NewCircuitBreakerBehavior(
+		CircuitBreakerSettings{FailureThreshold: 1},
+		CircuitBreakerFor[*Query1](CircuitBreakerSettings{FailureThreshold: 3}),
+	)
+
+	// act
+	behavior.Handle(context.TODO(), &Query1{}, failingNext)
+	behavior.Handle(context.TODO(), &Query1{}, failingNext)
+
+	// assert
+	assert.Equal(t, CircuitClosed, behavior.State("*cqrs.Query1"))
+}
+
+func TestCircuitBreakerBehavior_WhenErrorIsNotFailure_ShouldNotCountFailure(t *testing.T) {
+	// arrange
+	settings := CircuitBreakerSettings{
+		FailureThreshold: 1,
+		IsFailure: func(err error) bool {
+			var validationErr *ValidationError
+			return !errors.As(err, &validationErr)
+		},
+	}
+	behavior := NewCircuitBreakerBehavior(settings)
+	next := func(ctx context.Context, request interface{}) (interface{}, error) {
+		return nil, NewValidationError(FieldError{Field: "Name", Message: "is required"})
+	}
+
+	// act
+	behavior.Handle(context.TODO(), &Command1{}, next)
+
+	// assert
+	assert.Equal(t, CircuitClosed, behavior.State("*cqrs.Command1"))
+}
+
+func TestCircuitState_String_ShouldDescribeState(t *testing.T) {
+	// assert
+	assert.Equal(t, "closed", CircuitClosed.String())
+	assert.Equal(t, "open", CircuitOpen.String())
+	assert.Equal(t, "half-open", CircuitHalfOpen.String())
+}
+
+func TestCircuitBreakerBehavior_WhenCircuitIsHealthy_ShouldEvictIt(t *testing.T) {
+	// arrange
+	behavior := NewCircuitBreakerBehavior(CircuitBreakerSettings{FailureThreshold: 2})
+
+	// act
+	behavior.Handle(context.TODO(), &TenantCommand{Tenant: "a"}, succeedingNext)
+	behavior.Handle(context.TODO(), &TenantCommand{Tenant: "b"}, failingNext)
+
+	// assert
+	assert.Len(t, behavior.circuits, 1)
+	assert.Contains(t, behavior.circuits, "tenant:b")
+}
@@ -0,0 +1,527 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+const cqrsPath = "github.com/mitz-it/golang-cqrs"
+
+type handlerKind string
+
+const (
+	commandHandler     handlerKind = "command"
+	voidCommandHandler handlerKind = "void"
+	queryHandler       handlerKind = "query"
+	eventHandler       handlerKind = "event"
+)
+
+type handler struct {
+	Kind     handlerKind
+	Name     string
+	Message  string
+	Response string
+	Helper   string
+	message  types.Type
+	response types.Type
+}
+
+type generator struct {
+	Package  string
+	Type     string
+	Func     string
+	Handlers []handler
+	Helpers  []handler
+	pkg      *types.Package
+	imports  map[string]string
+}
+
+type listedPackage struct {
+	ImportPath string
+	Name       string
+	Dir        string
+	GoFiles    []string
+	Export     string
+	DepOnly    bool
+	ImportMap  map[string]string
+	Error      *struct {
+		Err string
+	}
+}
+
+type loadedPackage struct {
+	Name   string
+	Types  *types.Package
+	Syntax []*ast.File
+}
+
+func load(dir string, output string) (*loadedPackage, error) {
+	cmd := exec.Command("go", "list", "-e", "-export", "-deps", "-json", ".")
+	cmd.Dir = dir
+	cmd.Stderr = os.Stderr
+
+	stdout, err := cmd.Output()
+
+	if err != nil {
+		return nil, err
+	}
+
+	var target *listedPackage
+	exports := map[string]string{}
+	decoder := json.NewDecoder(bytes.NewReader(stdout))
+
+	for decoder.More() {
+		listed := &listedPackage{}
+
+		if err := decoder.Decode(listed); err != nil {
+			return nil, err
+		}
+
+		exports[listed.ImportPath] = listed.Export
+
+		if !listed.DepOnly {
+			target = listed
+		}
+	}
+
+	if target == nil {
+		msg := fmt.Sprintf("no package found in %s", dir)
+		return nil, errors.New(msg)
+	}
+
+	fset := token.NewFileSet()
+	files := []*ast.File{}
+
+	for _, name := range target.GoFiles {
+		// the generated file is skipped so a stale one doesn't break the package
+		if name == filepath.Base(output) {
+			continue
+		}
+
+		file, err := parser.ParseFile(fset, filepath.Join(target.Dir, name), nil, parser.ParseComments)
+
+		if err != nil {
+			return nil, err
+		}
+
+		files = append(files, file)
+	}
+
+	lookup := func(path string) (io.ReadCloser, error) {
+		if mapped, found := target.ImportMap[path]; found {
+			path = mapped
+		}
+
+		export, found := exports[path]
+
+		if !found || export == "" {
+			msg := fmt.Sprintf("no export data for package %s", path)
+			return nil, errors.New(msg)
+		}
+
+		return os.Open(export)
+	}
+
+	var typeErr error
+
+	config := &types.Config{
+		Importer: importer.ForCompiler(fset, "gc", lookup),
+		// without the generated file, the code calling it has undefined names
+		Error: func(err error) {
+			if checkErr, ok := err.(types.Error); ok && strings.HasPrefix(checkErr.Msg, "undefined: ") {
+				return
+			}
+
+			if typeErr == nil {
+				typeErr = err
+			}
+		},
+	}
+
+	pkg, _ := config.Check(target.ImportPath, fset, files, nil)
+
+	if typeErr != nil {
+		return nil, typeErr
+	}
+
+	return &loadedPackage{Name: target.Name, Types: pkg, Syntax: files}, nil
+}
+
+func scan(pkg *loadedPackage) ([]handler, error) {
+	directives := typeDirectives(pkg.Syntax)
+	handlers := []handler{}
+	scope := pkg.Types.Scope()
+
+	for _, name := range scope.Names() {
+		typeName, ok := scope.Lookup(name).(*types.TypeName)
+
+		if !ok || !typeName.Exported() || typeName.IsAlias() {
+			continue
+		}
+
+		named, ok := typeName.Type().(*types.Named)
+
+		if !ok || named.TypeParams().Len() > 0 {
+			continue
+		}
+
+		if _, isInterface := named.Underlying().(*types.Interface); isInterface {
+			continue
+		}
+
+		directive := directives[name]
+
+		if directive == "ignore" {
+			continue
+		}
+
+		h, ok, err := inspect(named, directive)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if ok {
+			handlers = append(handlers, h)
+		}
+	}
+
+	return handlers, nil
+}
+
+func inspect(named *types.Named, directive string) (handler, bool, error) {
+	object, _, _ := types.LookupFieldOrMethod(types.NewPointer(named), true, named.Obj().Pkg(), "Handle")
+	method, ok := object.(*types.Func)
+
+	if !ok {
+		return handler{}, false, nil
+	}
+
+	signature := method.Type().(*types.Signature)
+	params, results := signature.Params(), signature.Results()
+
+	if signature.Variadic() || params.Len() != 2 || !isContext(params.At(0).Type()) {
+		return handler{}, false, nil
+	}
+
+	if results.Len() == 0 || results.Len() > 2 || !isError(results.At(results.Len()-1).Type()) {
+		return handler{}, false, nil
+	}
+
+	message := params.At(1).Type()
+	h := handler{
+		Name:    named.Obj().Name(),
+		Helper:  messageName(message),
+		message: message,
+	}
+
+	void := results.Len() == 1
+
+	if !void {
+		h.response = results.At(0).Type()
+	}
+
+	kind, found := directiveKind(directive, void)
+
+	if !found {
+		kind, found = nameKind(h.Name, h.Helper, void)
+	}
+
+	if !found && h.Helper != "" {
+		msg := fmt.Sprintf("can't tell whether %s handles a command, a query or an event, add //cqrs:command, //cqrs:query or //cqrs:event to it", h.Name)
+		return handler{}, false, errors.New(msg)
+	}
+
+	h.Kind = kind
+
+	return h, h.Helper != "", nil
+}
+
+func typeDirectives(files []*ast.File) map[string]string {
+	directives := map[string]string{}
+
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+
+			for _, spec := range gen.Specs {
+				typeSpec := spec.(*ast.TypeSpec)
+				doc := typeSpec.Doc
+
+				if doc == nil && len(gen.Specs) == 1 {
+					doc = gen.Doc
+				}
+
+				if directive := directive(doc); directive != "" {
+					directives[typeSpec.Name.Name] = directive
+				}
+			}
+		}
+	}
+
+	return directives
+}
+
+func directive(doc *ast.CommentGroup) string {
+	if doc == nil {
+		return ""
+	}
+
+	for _, comment := range doc.List {
+		if value, found := strings.CutPrefix(comment.Text, "//cqrs:"); found {
+			return strings.TrimSpace(value)
+		}
+	}
+
+	return ""
+}
+
+func directiveKind(directive string, void bool) (handlerKind, bool) {
+	switch {
+	case directive == "command" && void:
+		return voidCommandHandler, true
+	case directive == "command":
+		return commandHandler, true
+	case directive == "query" && !void:
+		return queryHandler, true
+	case directive == "event" && void:
+		return eventHandler, true
+	}
+
+	return "", false
+}
+
+// only the Command, Query and Event suffixes are trusted, verbs like Get or
+// Count and past tense names are too often used for other kinds
+func nameKind(handlerName string, messageName string, void bool) (handlerKind, bool) {
+	switch {
+	case hasSuffix(handlerName, messageName, "Command") && void:
+		return voidCommandHandler, true
+	case hasSuffix(handlerName, messageName, "Command"):
+		return commandHandler, true
+	case hasSuffix(handlerName, messageName, "Query") && !void:
+		return queryHandler, true
+	case hasSuffix(handlerName, messageName, "Event") && void:
+		return eventHandler, true
+	}
+
+	return "", false
+}
+
+func hasSuffix(handlerName string, messageName string, suffix string) bool {
+	return strings.HasSuffix(handlerName, suffix+"Handler") || strings.HasSuffix(messageName, suffix)
+}
+
+func isContext(t types.Type) bool {
+	named, ok := t.(*types.Named)
+
+	return ok && named.Obj().Pkg() != nil && named.Obj().Pkg().Path() == "context" && named.Obj().Name() == "Context"
+}
+
+func isError(t types.Type) bool {
+	return types.Identical(t, types.Universe.Lookup("error").Type())
+}
+
+func messageName(t types.Type) string {
+	if pointer, ok := t.(*types.Pointer); ok {
+		t = pointer.Elem()
+	}
+
+	named, ok := t.(*types.Named)
+
+	if !ok || !named.Obj().Exported() {
+		return ""
+	}
+
+	return named.Obj().Name()
+}
+
+func newGenerator(pkg *loadedPackage, typeName string, funcName string) *generator {
+	return &generator{
+		Package: pkg.Name,
+		Type:    typeName,
+		Func:    funcName,
+		pkg:     pkg.Types,
+		imports: map[string]string{cqrsPath: "cqrs", "context": "context"},
+	}
+}
+
+func (g *generator) generate(pkg *loadedPackage) ([]byte, error) {
+	helpers := map[string]handler{}
+
+	handlers, err := scan(pkg)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, h := range handlers {
+		h.Message = types.TypeString(h.message, g.qualifier)
+
+		if h.response != nil {
+			h.Response = types.TypeString(h.response, g.qualifier)
+		}
+
+		g.Handlers = append(g.Handlers, h)
+
+		helper, found := helpers[h.Helper]
+
+		if !found {
+			helpers[h.Helper] = h
+			g.Helpers = append(g.Helpers, h)
+			continue
+		}
+
+		if h.Kind != eventHandler || helper.Kind != eventHandler || !types.Identical(h.message, helper.message) {
+			msg := fmt.Sprintf("%s and %s both handle messages named %s", helper.Name, h.Name, h.Helper)
+			return nil, errors.New(msg)
+		}
+	}
+
+	if len(g.Handlers) == 0 {
+		msg := fmt.Sprintf("no handlers found in package %s", g.Package)
+		return nil, errors.New(msg)
+	}
+
+	var buffer bytes.Buffer
+
+	if err := generatedTemplate.Execute(&buffer, g); err != nil {
+		return nil, err
+	}
+
+	return format.Source(buffer.Bytes())
+}
+
+func (g *generator) qualifier(pkg *types.Package) string {
+	if pkg == g.pkg {
+		return ""
+	}
+
+	if name, found := g.imports[pkg.Path()]; found {
+		return name
+	}
+
+	name := pkg.Name()
+	taken := func(name string) bool {
+		for _, imported := range g.imports {
+			if imported == name {
+				return true
+			}
+		}
+
+		return g.pkg.Scope().Lookup(name) != nil
+	}
+
+	for i := 2; taken(name); i++ {
+		name = pkg.Name() + strconv.Itoa(i)
+	}
+
+	g.imports[pkg.Path()] = name
+
+	return name
+}
+
+func (g *generator) Imports() []string {
+	std, others := []string{}, []string{}
+
+	for path, name := range g.imports {
+		spec := strconv.Quote(path)
+
+		if name != filepath.Base(path) {
+			spec = name + " " + spec
+		}
+
+		if strings.Contains(strings.Split(path, "/")[0], ".") {
+			others = append(others, spec)
+			continue
+		}
+
+		std = append(std, spec)
+	}
+
+	sort.Strings(std)
+	sort.Strings(others)
+
+	return append(append(std, ""), others...)
+}
+
+var generatedTemplate = template.Must(template.New("cqrsgen").Parse(`// Code generated by cqrsgen. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+{{- range .Imports}}
+	{{.}}
+{{- end}}
+)
+
+type {{.Type}} struct {
+{{- range .Handlers}}
+	{{.Name}} *{{.Name}}
+{{- end}}
+}
+
+func (h {{.Type}}) Registrations() []cqrs.Registration {
+	registrations := []cqrs.Registration{}
+{{range .Handlers}}
+	if h.{{.Name}} != nil {
+		{{- if eq .Kind "command"}}
+		registrations = append(registrations, cqrs.CommandHandler[{{.Message}}, {{.Response}}](h.{{.Name}}))
+		{{- else if eq .Kind "void"}}
+		registrations = append(registrations, cqrs.VoidCommandHandler[{{.Message}}](h.{{.Name}}))
+		{{- else if eq .Kind "query"}}
+		registrations = append(registrations, cqrs.QueryHandler[{{.Message}}, {{.Response}}](h.{{.Name}}))
+		{{- else}}
+		registrations = append(registrations, cqrs.EventSubscriber[{{.Message}}](h.{{.Name}}))
+		{{- end}}
+	}
+{{end}}
+	return registrations
+}
+
+func {{.Func}}(h {{.Type}}) error {
+	return cqrs.Register(h.Registrations()...)
+}
+{{range .Helpers}}
+{{- if eq .Kind "command"}}
+func Send{{.Helper}}(ctx context.Context, command {{.Message}}) ({{.Response}}, error) {
+	return cqrs.Send[{{.Message}}, {{.Response}}](ctx, command)
+}
+{{else if eq .Kind "void"}}
+func Send{{.Helper}}(ctx context.Context, command {{.Message}}) error {
+	return cqrs.SendVoid[{{.Message}}](ctx, command)
+}
+{{else if eq .Kind "query"}}
+func Request{{.Helper}}(ctx context.Context, query {{.Message}}) ({{.Response}}, error) {
+	return cqrs.Request[{{.Message}}, {{.Response}}](ctx, query)
+}
+{{else}}
+func Publish{{.Helper}}(ctx context.Context, event {{.Message}}) error {
+	return cqrs.PublishEvent[{{.Message}}](ctx, event)
+}
+
+func Publish{{.Helper}}Async(ctx context.Context, event {{.Message}}) error {
+	return cqrs.PublishEventAsync[{{.Message}}](ctx, event)
+}
+{{end}}
+{{- end}}`))
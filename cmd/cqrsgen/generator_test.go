@@ -0,0 +1,152 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerate_WhenPackageHasHandlers_ShouldMatchGeneratedFile(t *testing.T) {
+	// arrange
+	dir := filepath.Join("testdata", "products")
+	expected, _ := os.ReadFile(filepath.Join(dir, "cqrs_gen.go"))
+	pkg, loadErr := load(dir, "cqrs_gen.go")
+
+	// act
+	source, err := newGenerator(pkg, "Handlers", "RegisterHandlers").generate(pkg)
+
+	// assert
+	assert.Nil(t, loadErr)
+	assert.Nil(t, err)
+	assert.Equal(t, string(expected), string(source))
+}
+
+func TestGenerate_WhenPackageHasHandlers_ShouldGenerateCompilingFile(t *testing.T) {
+	// act
+	pkg, err := load(filepath.Join("testdata", "products"), "other_gen.go")
+
+	// assert
+	assert.Nil(t, err)
+	assert.NotNil(t, pkg.Types.Scope().Lookup("RegisterHandlers"))
+	assert.NotNil(t, pkg.Types.Scope().Lookup("SendCreateProduct"))
+}
+
+func TestGenerate_WhenPackageUsesGeneratedCode_ShouldMatchGeneratedFile(t *testing.T) {
+	// arrange
+	dir := filepath.Join("testdata", "catalog")
+	expected, _ := os.ReadFile(filepath.Join(dir, "cqrs_gen.go"))
+	pkg, loadErr := load(dir, "cqrs_gen.go")
+
+	// act
+	source, err := newGenerator(pkg, "Handlers", "RegisterHandlers").generate(pkg)
+
+	// assert
+	assert.Nil(t, loadErr)
+	assert.Nil(t, err)
+	assert.Equal(t, string(expected), string(source))
+}
+
+func TestGenerate_WhenGeneratedFileIsStale_ShouldIgnoreIt(t *testing.T) {
+	// arrange
+	dir := filepath.Join("testdata", "stale")
+	pkg, loadErr := load(dir, "cqrs_gen.go")
+
+	// act
+	source, err := newGenerator(pkg, "Handlers", "RegisterHandlers").generate(pkg)
+
+	// assert
+	assert.Nil(t, loadErr)
+	assert.Nil(t, err)
+	assert.Contains(t, string(source), "func SendPlaceOrder(ctx context.Context, command *PlaceOrder) (string, error) {")
+	assert.NotContains(t, string(source), "CancelOrderHandler")
+}
+
+func TestGenerate_WhenPackageHasNoHandlers_ShouldReturnError(t *testing.T) {
+	// arrange
+	pkg, _ := load(filepath.Join("testdata", "empty"), "cqrs_gen.go")
+
+	// act
+	_, err := newGenerator(pkg, "Handlers", "RegisterHandlers").generate(pkg)
+
+	// assert
+	assert.EqualError(t, err, "no handlers found in package empty")
+}
+
+func TestRun_WhenNamesProvided_ShouldWriteFileWithThoseNames(t *testing.T) {
+	// arrange
+	dir := filepath.Join("testdata", "orders")
+	output := "orders_gen.go"
+	defer os.Remove(filepath.Join(dir, output))
+
+	// act
+	err := run(dir, output, "OrderHandlers", "RegisterOrderHandlers")
+
+	// assert
+	source, _ := os.ReadFile(filepath.Join(dir, output))
+	assert.Nil(t, err)
+	assert.Contains(t, string(source), "type OrderHandlers struct {")
+	assert.Contains(t, string(source), "func RegisterOrderHandlers(h OrderHandlers) error {")
+}
+
+func TestGenerate_WhenHandlerKindIsUnclear_ShouldReturnError(t *testing.T) {
+	// arrange
+	pkg, loadErr := load(filepath.Join("testdata", "ambiguous"), "cqrs_gen.go")
+
+	// act
+	_, err := newGenerator(pkg, "Handlers", "RegisterHandlers").generate(pkg)
+
+	// assert
+	assert.Nil(t, loadErr)
+	assert.EqualError(t, err, "can't tell whether CountVotesHandler handles a command, a query or an event, add //cqrs:command, //cqrs:query or //cqrs:event to it")
+}
+
+func TestNameKind_WhenNamedAsQuery_ShouldReturnQuery(t *testing.T) {
+	// act
+	fromMessage, messageFound := nameKind("ProductHandler", "ProductQuery", false)
+	fromHandler, handlerFound := nameKind("ProductQueryHandler", "Product", false)
+
+	// assert
+	assert.True(t, messageFound)
+	assert.True(t, handlerFound)
+	assert.Equal(t, queryHandler, fromMessage)
+	assert.Equal(t, queryHandler, fromHandler)
+}
+
+func TestNameKind_WhenNamedAsCommand_ShouldReturnCommand(t *testing.T) {
+	// act
+	fromMessage, messageFound := nameKind("ProductHandler", "ProductCommand", false)
+	fromHandler, handlerFound := nameKind("ProductCommandHandler", "Product", false)
+	void, voidFound := nameKind("ArchiveProductCommandHandler", "ArchiveProductCommand", true)
+
+	// assert
+	assert.True(t, messageFound)
+	assert.True(t, handlerFound)
+	assert.True(t, voidFound)
+	assert.Equal(t, commandHandler, fromMessage)
+	assert.Equal(t, commandHandler, fromHandler)
+	assert.Equal(t, voidCommandHandler, void)
+}
+
+func TestNameKind_WhenNamedAsEvent_ShouldReturnEvent(t *testing.T) {
+	// act
+	kind, found := nameKind("ProductCreatedHandler", "ProductCreatedEvent", true)
+
+	// assert
+	assert.True(t, found)
+	assert.Equal(t, eventHandler, kind)
+}
+
+func TestNameKind_WhenNameHasNoSuffix_ShouldNotGuess(t *testing.T) {
+	// assert
+	for _, name := range []string{"GetProduct", "ListProducts", "CountVotes", "FindOrCreateUser", "CreateProduct"} {
+		_, found := nameKind(name+"Handler", name, false)
+		assert.False(t, found, name)
+	}
+
+	for _, name := range []string{"ProductCreated", "DeleteProduct", "ProductQuery"} {
+		_, found := nameKind(name+"Handler", name, true)
+		assert.False(t, found, name)
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+func main() {
+	output := flag.String("output", "cqrs_gen.go", "name of the generated file, relative to the package directory")
+	typeName := flag.String("type", "Handlers", "name of the generated struct holding the handlers")
+	funcName := flag.String("func", "RegisterHandlers", "name of the generated registration function")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: cqrsgen [flags] [directory]")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	dir := "."
+
+	if flag.NArg() > 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if flag.NArg() == 1 {
+		dir = flag.Arg(0)
+	}
+
+	if err := run(dir, *output, *typeName, *funcName); err != nil {
+		fmt.Fprintln(os.Stderr, "cqrsgen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(dir string, output string, typeName string, funcName string) error {
+	pkg, err := load(dir, output)
+
+	if err != nil {
+		return err
+	}
+
+	source, err := newGenerator(pkg, typeName, funcName).generate(pkg)
+
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(dir, output), source, 0o644)
+}
@@ -0,0 +1,14 @@
+package votes
+
+import "context"
+
+type CountVotes struct {
+	PollID string
+}
+
+type CountVotesHandler struct {
+}
+
+func (h *CountVotesHandler) Handle(ctx context.Context, command *CountVotes) (int, error) {
+	return 0, nil
+}
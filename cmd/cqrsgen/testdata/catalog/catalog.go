@@ -0,0 +1,44 @@
+package catalog
+
+import "context"
+
+type Item struct {
+	ID string
+}
+
+type AddItemCommand struct {
+	Name string
+}
+
+type AddItemCommandHandler struct {
+}
+
+func (h *AddItemCommandHandler) Handle(ctx context.Context, command *AddItemCommand) (*Item, error) {
+	return &Item{ID: command.Name}, nil
+}
+
+type RemoveItemCommand struct {
+	ID string
+}
+
+type RemoveItemCommandHandler struct {
+}
+
+func (h *RemoveItemCommandHandler) Handle(ctx context.Context, command *RemoveItemCommand) error {
+	return nil
+}
+
+func Setup() error {
+	return RegisterHandlers(Handlers{
+		AddItemCommandHandler:    &AddItemCommandHandler{},
+		RemoveItemCommandHandler: &RemoveItemCommandHandler{},
+	})
+}
+
+func AddItem(ctx context.Context, name string) (*Item, error) {
+	return SendAddItemCommand(ctx, &AddItemCommand{Name: name})
+}
+
+func RemoveItem(ctx context.Context, id string) error {
+	return SendRemoveItemCommand(ctx, &RemoveItemCommand{ID: id})
+}
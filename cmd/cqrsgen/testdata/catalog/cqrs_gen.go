@@ -0,0 +1,40 @@
+// Code generated by cqrsgen. DO NOT EDIT.
+
+package catalog
+
+import (
+	"context"
+
+	cqrs "github.com/mitz-it/golang-cqrs"
+)
+
+type Handlers struct {
+	AddItemCommandHandler    *AddItemCommandHandler
+	RemoveItemCommandHandler *RemoveItemCommandHandler
+}
+
+func (h Handlers) Registrations() []cqrs.Registration {
+	registrations := []cqrs.Registration{}
+
+	if h.AddItemCommandHandler != nil {
+		registrations = append(registrations, cqrs.CommandHandler[*AddItemCommand, *Item](h.AddItemCommandHandler))
+	}
+
+	if h.RemoveItemCommandHandler != nil {
+		registrations = append(registrations, cqrs.VoidCommandHandler[*RemoveItemCommand](h.RemoveItemCommandHandler))
+	}
+
+	return registrations
+}
+
+func RegisterHandlers(h Handlers) error {
+	return cqrs.Register(h.Registrations()...)
+}
+
+func SendAddItemCommand(ctx context.Context, command *AddItemCommand) (*Item, error) {
+	return cqrs.Send[*AddItemCommand, *Item](ctx, command)
+}
+
+func SendRemoveItemCommand(ctx context.Context, command *RemoveItemCommand) error {
+	return cqrs.SendVoid[*RemoveItemCommand](ctx, command)
+}
@@ -0,0 +1,4 @@
+package empty
+
+type Order struct {
+}
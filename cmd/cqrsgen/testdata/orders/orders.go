@@ -0,0 +1,14 @@
+package orders
+
+import "context"
+
+type CancelOrder struct {
+}
+
+//cqrs:command
+type CancelOrderHandler struct {
+}
+
+func (h *CancelOrderHandler) Handle(ctx context.Context, command *CancelOrder) (bool, error) {
+	return true, nil
+}
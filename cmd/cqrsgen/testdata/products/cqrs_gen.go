@@ -0,0 +1,77 @@
+// Code generated by cqrsgen. DO NOT EDIT.
+
+package products
+
+import (
+	"context"
+	"time"
+
+	cqrs "github.com/mitz-it/golang-cqrs"
+)
+
+type Handlers struct {
+	CreateProductHandler     *CreateProductHandler
+	DeleteProductHandler     *DeleteProductHandler
+	GetProductHandler        *GetProductHandler
+	ProductCreatedHandler    *ProductCreatedHandler
+	ProductCreatedNotifier   *ProductCreatedNotifier
+	ProductExpirationHandler *ProductExpirationHandler
+}
+
+func (h Handlers) Registrations() []cqrs.Registration {
+	registrations := []cqrs.Registration{}
+
+	if h.CreateProductHandler != nil {
+		registrations = append(registrations, cqrs.CommandHandler[*CreateProduct, *Product](h.CreateProductHandler))
+	}
+
+	if h.DeleteProductHandler != nil {
+		registrations = append(registrations, cqrs.VoidCommandHandler[*DeleteProduct](h.DeleteProductHandler))
+	}
+
+	if h.GetProductHandler != nil {
+		registrations = append(registrations, cqrs.QueryHandler[*GetProduct, *Product](h.GetProductHandler))
+	}
+
+	if h.ProductCreatedHandler != nil {
+		registrations = append(registrations, cqrs.EventSubscriber[*ProductCreated](h.ProductCreatedHandler))
+	}
+
+	if h.ProductCreatedNotifier != nil {
+		registrations = append(registrations, cqrs.EventSubscriber[*ProductCreated](h.ProductCreatedNotifier))
+	}
+
+	if h.ProductExpirationHandler != nil {
+		registrations = append(registrations, cqrs.QueryHandler[*ProductExpiration, time.Time](h.ProductExpirationHandler))
+	}
+
+	return registrations
+}
+
+func RegisterHandlers(h Handlers) error {
+	return cqrs.Register(h.Registrations()...)
+}
+
+func SendCreateProduct(ctx context.Context, command *CreateProduct) (*Product, error) {
+	return cqrs.Send[*CreateProduct, *Product](ctx, command)
+}
+
+func SendDeleteProduct(ctx context.Context, command *DeleteProduct) error {
+	return cqrs.SendVoid[*DeleteProduct](ctx, command)
+}
+
+func RequestGetProduct(ctx context.Context, query *GetProduct) (*Product, error) {
+	return cqrs.Request[*GetProduct, *Product](ctx, query)
+}
+
+func PublishProductCreated(ctx context.Context, event *ProductCreated) error {
+	return cqrs.PublishEvent[*ProductCreated](ctx, event)
+}
+
+func PublishProductCreatedAsync(ctx context.Context, event *ProductCreated) error {
+	return cqrs.PublishEventAsync[*ProductCreated](ctx, event)
+}
+
+func RequestProductExpiration(ctx context.Context, query *ProductExpiration) (time.Time, error) {
+	return cqrs.Request[*ProductExpiration, time.Time](ctx, query)
+}
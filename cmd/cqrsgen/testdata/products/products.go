@@ -0,0 +1,93 @@
+package products
+
+import (
+	"context"
+	"time"
+)
+
+type Product struct {
+	ID string
+}
+
+type CreateProduct struct {
+	Name string
+}
+
+//cqrs:command
+type CreateProductHandler struct {
+}
+
+func (h *CreateProductHandler) Handle(ctx context.Context, command *CreateProduct) (*Product, error) {
+	return &Product{ID: command.Name}, nil
+}
+
+type DeleteProduct struct {
+	ID string
+}
+
+//cqrs:command
+type DeleteProductHandler struct {
+}
+
+func (h *DeleteProductHandler) Handle(ctx context.Context, command *DeleteProduct) error {
+	return nil
+}
+
+type GetProduct struct {
+	ID string
+}
+
+//cqrs:query
+type GetProductHandler struct {
+}
+
+func (h *GetProductHandler) Handle(ctx context.Context, query *GetProduct) (*Product, error) {
+	return &Product{ID: query.ID}, nil
+}
+
+type ProductExpiration struct {
+	ID string
+}
+
+//cqrs:query
+type ProductExpirationHandler struct {
+}
+
+func (h ProductExpirationHandler) Handle(ctx context.Context, query *ProductExpiration) (time.Time, error) {
+	return time.Time{}, nil
+}
+
+type ProductCreated struct {
+	ID string
+}
+
+//cqrs:event
+type ProductCreatedHandler struct {
+}
+
+func (h *ProductCreatedHandler) Handle(ctx context.Context, event *ProductCreated) error {
+	return nil
+}
+
+//cqrs:event
+type ProductCreatedNotifier struct {
+}
+
+func (h *ProductCreatedNotifier) Handle(ctx context.Context, event *ProductCreated) error {
+	return nil
+}
+
+//cqrs:ignore
+type ProductCreatedLegacyHandler struct {
+}
+
+func (h *ProductCreatedLegacyHandler) Handle(ctx context.Context, event *ProductCreated) error {
+	return nil
+}
+
+type ProductRepository struct {
+}
+
+func (r *ProductRepository) Handle(id string) error {
+	return nil
+}
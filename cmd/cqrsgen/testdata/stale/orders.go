@@ -0,0 +1,14 @@
+package orders
+
+import "context"
+
+type PlaceOrder struct {
+}
+
+//cqrs:command
+type PlaceOrderHandler struct {
+}
+
+func (h *PlaceOrderHandler) Handle(ctx context.Context, command *PlaceOrder) (string, error) {
+	return "", nil
+}
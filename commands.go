@@ -5,14 +5,16 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
-
-	"github.com/ahmetb/go-linq/v3"
 )
 
 type ICommandHandler[TCommand any, TResponse any] interface {
 	Handle(ctx context.Context, command TCommand) (TResponse, error)
 }
 
+type IVoidCommandHandler[TCommand any] interface {
+	Handle(ctx context.Context, command TCommand) error
+}
+
 var commandHandlers map[reflect.Type]interface{}
 
 func init() {
@@ -20,6 +22,14 @@ func init() {
 }
 
 func RegisterCommandHandler[TCommand any, TResponse any](handler ICommandHandler[TCommand, TResponse]) error {
+	return registerCommandHandler[TCommand](handler)
+}
+
+func RegisterVoidCommandHandler[TCommand any](handler IVoidCommandHandler[TCommand]) error {
+	return registerCommandHandler[TCommand](handler)
+}
+
+func registerCommandHandler[TCommand any](handler interface{}) error {
 	var command TCommand
 	commandType := reflect.TypeOf(command)
 
@@ -52,26 +62,24 @@ func Send[TCommand any, TResponse any](ctx context.Context, command TCommand) (T
 		return *new(TResponse), errors.New(msg)
 	}
 
-	if len(commandBehaviors) <= 0 {
-		return handler.Handle(ctx, command)
-	}
+	commandHandle := func(ctx context.Context, request interface{}) (interface{}, error) {
+		command, casted := request.(TCommand)
 
-	sortedBehaviors := sortBehaviors(commandBehaviors)
+		if !casted {
+			msg := fmt.Sprintf("command of type %T can't be replaced by a request of type %T", *new(TCommand), request)
+			return nil, errors.New(msg)
+		}
 
-	commandHandle := func() (interface{}, error) {
 		return handler.Handle(ctx, command)
 	}
 
-	aggregatedPipeline := linq.From(sortedBehaviors).AggregateWithSeedT(commandHandle, func(next NextFunc, b IBehavior) NextFunc {
-		var nextFunc NextFunc = func() (interface{}, error) {
-			return b.Handle(ctx, command, next)
-		}
-		return nextFunc
-	})
-
-	pipeline := aggregatedPipeline.(NextFunc)
+	info := DispatchInfo{
+		Kind:        CommandMessage,
+		MessageType: commandType.String(),
+		HandlerType: handlerName(h),
+	}
 
-	res, err := pipeline()
+	res, err := dispatch(ctx, info, command, commandBehaviors, commandHandle)
 
 	response, casted := res.(TResponse)
 
@@ -81,3 +89,42 @@ func Send[TCommand any, TResponse any](ctx context.Context, command TCommand) (T
 
 	return response, err
 }
+
+func SendVoid[TCommand any](ctx context.Context, command TCommand) error {
+	commandType := reflect.TypeOf(command)
+
+	h, found := commandHandlers[commandType]
+
+	if !found {
+		msg := fmt.Sprintf("no handler registered for command %T", command)
+		return errors.New(msg)
+	}
+
+	handler, casted := h.(IVoidCommandHandler[TCommand])
+
+	if !casted {
+		msg := fmt.Sprintf("handler of type %T is not assignable for void command of type %T", h, command)
+		return errors.New(msg)
+	}
+
+	commandHandle := func(ctx context.Context, request interface{}) (interface{}, error) {
+		command, casted := request.(TCommand)
+
+		if !casted {
+			msg := fmt.Sprintf("command of type %T can't be replaced by a request of type %T", *new(TCommand), request)
+			return nil, errors.New(msg)
+		}
+
+		return nil, handler.Handle(ctx, command)
+	}
+
+	info := DispatchInfo{
+		Kind:        CommandMessage,
+		MessageType: commandType.String(),
+		HandlerType: handlerName(h),
+	}
+
+	_, err := dispatch(ctx, info, command, commandBehaviors, commandHandle)
+
+	return err
+}
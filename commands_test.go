@@ -2,6 +2,7 @@ package cqrs
 
 import (
 	"context"
+	"errors"
 	"reflect"
 	"testing"
 
@@ -101,6 +102,7 @@ func TestSend_WhenNoCommandBehaviors_ShouldCallHandler(t *testing.T) {
 func TestSend_WhenHaveCommandBehaviors_ShouldCallHandlerThroughPipeline(t *testing.T) {
 	// arrange
 	defer commands_cleanup(t)
+	defer behaviors_cleanup(t)
 	command := &Command1{}
 	handler := &CommandHandler1{}
 	RegisterCommandBehavior(0, &Behavior1{})
@@ -118,6 +120,7 @@ func TestSend_WhenHaveCommandBehaviors_ShouldCallHandlerThroughPipeline(t *testi
 func TestSend_WhenHaveCommandBehaviorsAndResponseCanBeCasted_ShouldDefaultResponse(t *testing.T) {
 	// arrange
 	defer commands_cleanup(t)
+	defer behaviors_cleanup(t)
 	command := &Command1{}
 	handler := &CommandHandler1{}
 	RegisterCommandBehavior(0, &Behavior3{})
@@ -128,5 +131,116 @@ func TestSend_WhenHaveCommandBehaviorsAndResponseCanBeCasted_ShouldDefaultRespon
 
 	// assert
 	assert.Nil(t, err)
-	assert.Equal(t, &Response{}, res)
+	assert.Nil(t, res)
+}
+
+type ContextCommandHandler struct {
+	tenant interface{}
+}
+
+func (h *ContextCommandHandler) Handle(ctx context.Context, command *Command1) (*Response, error) {
+	h.tenant = ctx.Value(tenantContextKey{})
+	return &Response{}, nil
+}
+
+func TestSend_WhenBehaviorChangesContext_ShouldPassContextToHandler(t *testing.T) {
+	// arrange
+	defer commands_cleanup(t)
+	defer behaviors_cleanup(t)
+	handler := &ContextCommandHandler{}
+	RegisterCommandPipelineBehavior(0, &TenantBehavior{})
+	RegisterCommandHandler[*Command1, *Response](handler)
+
+	// act
+	_, err := Send[*Command1, *Response](context.TODO(), &Command1{})
+
+	// assert
+	assert.Nil(t, err)
+	assert.Equal(t, "tenant", handler.tenant)
+}
+
+func TestSend_WhenBehaviorReplacesCommandWithAnotherType_ShouldReturnError(t *testing.T) {
+	// arrange
+	defer commands_cleanup(t)
+	defer behaviors_cleanup(t)
+	RegisterCommandPipelineBehavior(0, &ReplacingBehavior{request: &Query1{}})
+	RegisterCommandHandler[*Command1, *Response](&CommandHandler1{})
+
+	// act
+	_, err := Send[*Command1, *Response](context.TODO(), &Command1{})
+
+	// assert
+	assert.Error(t, err)
+}
+
+type VoidCommandHandler1 struct {
+	calls int
+	err   error
+}
+
+func (h *VoidCommandHandler1) Handle(ctx context.Context, command *Command1) error {
+	h.calls++
+	return h.err
+}
+
+func TestRegisterVoidCommandHandler_WhenHandlerAlreadyRegistered_ShouldReturnError(t *testing.T) {
+	// arrange
+	defer commands_cleanup(t)
+	RegisterCommandHandler[*Command1, *Response](&CommandHandler1{})
+
+	// act
+	err := RegisterVoidCommandHandler[*Command1](&VoidCommandHandler1{})
+
+	// assert
+	assert.EqualError(t, err, "handler for command of type *cqrs.Command1 is already registered")
+}
+
+func TestSendVoid_WhenHandlerRegistered_ShouldCallHandlerThroughPipeline(t *testing.T) {
+	// arrange
+	defer commands_cleanup(t)
+	defer behaviors_cleanup(t)
+	handler := &VoidCommandHandler1{}
+	behavior := &CountingBehavior{}
+	RegisterVoidCommandHandler[*Command1](handler)
+	RegisterCommandPipelineBehavior(0, behavior)
+
+	// act
+	err := SendVoid(context.TODO(), &Command1{})
+
+	// assert
+	assert.Nil(t, err)
+	assert.Equal(t, 1, handler.calls)
+	assert.Len(t, behavior.requests, 1)
+}
+
+func TestSendVoid_WhenHandlerFails_ShouldReturnError(t *testing.T) {
+	// arrange
+	defer commands_cleanup(t)
+	RegisterVoidCommandHandler[*Command1](&VoidCommandHandler1{err: errors.New("failed")})
+
+	// act
+	err := SendVoid(context.TODO(), &Command1{})
+
+	// assert
+	assert.EqualError(t, err, "failed")
+}
+
+func TestSendVoid_WhenHandlerHasResponse_ShouldReturnError(t *testing.T) {
+	// arrange
+	defer commands_cleanup(t)
+	RegisterCommandHandler[*Command1, *Response](&CommandHandler1{})
+
+	// act
+	err := SendVoid(context.TODO(), &Command1{})
+
+	// assert
+	assert.EqualError(t, err, "handler of type *cqrs.CommandHandler1 is not assignable for void command of type *cqrs.Command1")
+}
+
+func TestSendVoid_WhenNoHandlerRegistered_ShouldReturnError(t *testing.T) {
+	// act
+	err := SendVoid(context.TODO(), &Command1{})
+
+	// assert
+	assert.Error(t, err)
 }
@@ -0,0 +1,168 @@
+package cqrs
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+var registrationType = reflect.TypeOf(Registration(nil))
+var registrationsType = reflect.TypeOf([]Registration(nil))
+
+type Container struct {
+	mutex         sync.Mutex
+	constructors  map[reflect.Type]reflect.Value
+	instances     map[reflect.Type]reflect.Value
+	registrations []reflect.Value
+	resolving     map[reflect.Type]bool
+}
+
+func NewContainer() *Container {
+	return &Container{
+		constructors: make(map[reflect.Type]reflect.Value),
+		instances:    make(map[reflect.Type]reflect.Value),
+		resolving:    make(map[reflect.Type]bool),
+	}
+}
+
+func (c *Container) Provide(constructor interface{}) error {
+	value := reflect.ValueOf(constructor)
+
+	if value.Kind() != reflect.Func || value.IsNil() {
+		msg := fmt.Sprintf("constructor of type %T must be a function", constructor)
+		return errors.New(msg)
+	}
+
+	constructorType := value.Type()
+	outputs := constructorType.NumOut()
+
+	if outputs < 1 || outputs > 2 || (outputs == 2 && constructorType.Out(1) != errorType) {
+		msg := fmt.Sprintf("constructor of type %T must return a value and optionally an error", constructor)
+		return errors.New(msg)
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	providedType := constructorType.Out(0)
+
+	if providedType == registrationType || providedType == registrationsType {
+		c.registrations = append(c.registrations, value)
+		return nil
+	}
+
+	_, found := c.constructors[providedType]
+
+	if found {
+		msg := fmt.Sprintf("a constructor for type %s is already provided", providedType.String())
+		return errors.New(msg)
+	}
+
+	c.constructors[providedType] = value
+
+	return nil
+}
+
+func (c *Container) Resolve(target interface{}) error {
+	value := reflect.ValueOf(target)
+
+	if value.Kind() != reflect.Pointer || value.IsNil() {
+		msg := fmt.Sprintf("target of type %T must be a non-nil pointer", target)
+		return errors.New(msg)
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	resolved, err := c.resolve(value.Elem().Type())
+
+	if err != nil {
+		return err
+	}
+
+	value.Elem().Set(resolved)
+
+	return nil
+}
+
+func (c *Container) Register() error {
+	c.mutex.Lock()
+
+	registrations := []Registration{}
+
+	for _, constructor := range c.registrations {
+		provided, err := c.call(constructor)
+
+		if err != nil {
+			c.mutex.Unlock()
+			return err
+		}
+
+		switch r := provided.Interface().(type) {
+		case Registration:
+			registrations = append(registrations, r)
+		case []Registration:
+			registrations = append(registrations, r...)
+		}
+	}
+
+	c.mutex.Unlock()
+
+	return Register(registrations...)
+}
+
+func (c *Container) resolve(providedType reflect.Type) (reflect.Value, error) {
+	if instance, found := c.instances[providedType]; found {
+		return instance, nil
+	}
+
+	constructor, found := c.constructors[providedType]
+
+	if !found {
+		msg := fmt.Sprintf("no constructor provided for type %s", providedType.String())
+		return reflect.Value{}, errors.New(msg)
+	}
+
+	if c.resolving[providedType] {
+		msg := fmt.Sprintf("dependency cycle detected while resolving type %s", providedType.String())
+		return reflect.Value{}, errors.New(msg)
+	}
+
+	c.resolving[providedType] = true
+	defer delete(c.resolving, providedType)
+
+	instance, err := c.call(constructor)
+
+	if err != nil {
+		return reflect.Value{}, err
+	}
+
+	c.instances[providedType] = instance
+
+	return instance, nil
+}
+
+func (c *Container) call(constructor reflect.Value) (reflect.Value, error) {
+	constructorType := constructor.Type()
+	args := make([]reflect.Value, constructorType.NumIn())
+
+	for i := range args {
+		arg, err := c.resolve(constructorType.In(i))
+
+		if err != nil {
+			return reflect.Value{}, err
+		}
+
+		args[i] = arg
+	}
+
+	results := constructor.Call(args)
+
+	if len(results) == 2 && !results[1].IsNil() {
+		return reflect.Value{}, results[1].Interface().(error)
+	}
+
+	return results[0], nil
+}
@@ -0,0 +1,143 @@
+package cqrs
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type ProductRepository struct {
+	name string
+}
+
+type RepositoryCommandHandler struct {
+	repository *ProductRepository
+}
+
+func (h *RepositoryCommandHandler) Handle(ctx context.Context, command *Command1) (*Response, error) {
+	return &Response{}, nil
+}
+
+func newRepositoryCommandHandler(repository *ProductRepository) *RepositoryCommandHandler {
+	return &RepositoryCommandHandler{repository: repository}
+}
+
+func TestContainer_WhenResolvingType_ShouldInjectDependencies(t *testing.T) {
+	// arrange
+	container := NewContainer()
+	created := 0
+	container.Provide(func() *ProductRepository {
+		created++
+		return &ProductRepository{name: "products"}
+	})
+	container.Provide(newRepositoryCommandHandler)
+
+	// act
+	var handler *RepositoryCommandHandler
+	err := container.Resolve(&handler)
+	var repository *ProductRepository
+	container.Resolve(&repository)
+
+	// assert
+	assert.Nil(t, err)
+	assert.Same(t, repository, handler.repository)
+	assert.Equal(t, 1, created)
+}
+
+func TestContainer_WhenRegistrationsProvided_ShouldRegisterHandlers(t *testing.T) {
+	// arrange
+	defer commands_cleanup(t)
+	defer events_cleanup(t)
+	container := NewContainer()
+	container.Provide(func() *ProductRepository {
+		return &ProductRepository{}
+	})
+	container.Provide(newRepositoryCommandHandler)
+	container.Provide(func(handler *RepositoryCommandHandler) Registration {
+		return CommandHandler[*Command1, *Response](handler)
+	})
+	container.Provide(func() []Registration {
+		return []Registration{
+			EventSubscriber[*FakeEvent](&FakeEventHandler1{}),
+			EventSubscriber[*FakeEvent](&CountingFakeEventHandler{}),
+		}
+	})
+
+	// act
+	err := container.Register()
+
+	// assert
+	res, sendErr := Send[*Command1, *Response](context.TODO(), &Command1{})
+	assert.Nil(t, err)
+	assert.Nil(t, sendErr)
+	assert.NotNil(t, res)
+	assert.Nil(t, PublishEvent(context.TODO(), &FakeEvent{}))
+}
+
+func TestContainer_WhenConstructorFails_ShouldReturnError(t *testing.T) {
+	// arrange
+	container := NewContainer()
+	container.Provide(func() (*ProductRepository, error) {
+		return nil, errors.New("no connection")
+	})
+	container.Provide(newRepositoryCommandHandler)
+
+	// act
+	var handler *RepositoryCommandHandler
+	err := container.Resolve(&handler)
+
+	// assert
+	assert.EqualError(t, err, "no connection")
+	assert.Nil(t, handler)
+}
+
+func TestContainer_WhenDependencyMissing_ShouldReturnError(t *testing.T) {
+	// arrange
+	container := NewContainer()
+	container.Provide(newRepositoryCommandHandler)
+
+	// act
+	var handler *RepositoryCommandHandler
+	err := container.Resolve(&handler)
+
+	// assert
+	assert.EqualError(t, err, "no constructor provided for type *cqrs.ProductRepository")
+}
+
+func TestContainer_WhenDependenciesAreCyclic_ShouldReturnError(t *testing.T) {
+	// arrange
+	container := NewContainer()
+	container.Provide(func(handler *RepositoryCommandHandler) *ProductRepository {
+		return &ProductRepository{}
+	})
+	container.Provide(newRepositoryCommandHandler)
+
+	// act
+	var handler *RepositoryCommandHandler
+	err := container.Resolve(&handler)
+
+	// assert
+	assert.EqualError(t, err, "dependency cycle detected while resolving type *cqrs.RepositoryCommandHandler")
+}
+
+func TestContainer_WhenTypeProvidedTwice_ShouldReturnError(t *testing.T) {
+	// arrange
+	container := NewContainer()
+	container.Provide(newRepositoryCommandHandler)
+
+	// act
+	err := container.Provide(newRepositoryCommandHandler)
+
+	// assert
+	assert.Error(t, err)
+}
+
+func TestContainer_WhenConstructorIsNotAFunction_ShouldReturnError(t *testing.T) {
+	// act
+	err := NewContainer().Provide(&ProductRepository{})
+
+	// assert
+	assert.Error(t, err)
+}
@@ -0,0 +1,81 @@
+package cqrs
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type MessageIDs struct {
+	MessageID     string
+	CorrelationID string
+	CausationID   string
+}
+
+type messageIDContextKey struct{}
+
+type correlationIDContextKey struct{}
+
+type causationIDContextKey struct{}
+
+var generateMessageID = uuid.NewString
+
+func SetMessageIDGenerator(generator func() string) {
+	if generator == nil {
+		generateMessageID = uuid.NewString
+		return
+	}
+
+	generateMessageID = generator
+}
+
+func WithCorrelationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, correlationIDContextKey{}, id)
+}
+
+func WithCausationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, causationIDContextKey{}, id)
+}
+
+func MessageIDFromContext(ctx context.Context) (string, bool) {
+	return stringFromContext(ctx, messageIDContextKey{})
+}
+
+func CorrelationIDFromContext(ctx context.Context) (string, bool) {
+	return stringFromContext(ctx, correlationIDContextKey{})
+}
+
+func CausationIDFromContext(ctx context.Context) (string, bool) {
+	return stringFromContext(ctx, causationIDContextKey{})
+}
+
+func MessageIDsFromContext(ctx context.Context) MessageIDs {
+	messageID, _ := MessageIDFromContext(ctx)
+	correlationID, _ := CorrelationIDFromContext(ctx)
+	causationID, _ := CausationIDFromContext(ctx)
+
+	return MessageIDs{
+		MessageID:     messageID,
+		CorrelationID: correlationID,
+		CausationID:   causationID,
+	}
+}
+
+func withMessageIDs(ctx context.Context) context.Context {
+	id := generateMessageID()
+
+	if parent, ok := MessageIDFromContext(ctx); ok {
+		ctx = WithCausationID(ctx, parent)
+	}
+
+	if _, ok := CorrelationIDFromContext(ctx); !ok {
+		ctx = WithCorrelationID(ctx, id)
+	}
+
+	return context.WithValue(ctx, messageIDContextKey{}, id)
+}
+
+func stringFromContext(ctx context.Context, key interface{}) (string, bool) {
+	value, ok := ctx.Value(key).(string)
+	return value, ok && value != ""
+}
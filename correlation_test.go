@@ -0,0 +1,152 @@
+package cqrs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type PlaceOrder struct {
+}
+
+type OrderPlaced struct {
+}
+
+type ShipOrder struct {
+}
+
+type chainRecorder struct {
+	mutex sync.Mutex
+	ids   map[string]MessageIDs
+}
+
+func (r *chainRecorder) record(name string, ctx context.Context) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.ids[name] = MessageIDsFromContext(ctx)
+}
+
+type PlaceOrderHandler struct {
+	recorder *chainRecorder
+}
+
+func (h *PlaceOrderHandler) Handle(ctx context.Context, command *PlaceOrder) (*Response, error) {
+	h.recorder.record("place", ctx)
+	return &Response{}, PublishEvent(ctx, &OrderPlaced{})
+}
+
+type OrderPlacedHandler struct {
+	recorder *chainRecorder
+}
+
+func (h *OrderPlacedHandler) Handle(ctx context.Context, event *OrderPlaced) error {
+	h.recorder.record("placed", ctx)
+	_, err := Send[*ShipOrder, *Response](ctx, &ShipOrder{})
+	return err
+}
+
+type ShipOrderHandler struct {
+	recorder *chainRecorder
+}
+
+func (h *ShipOrderHandler) Handle(ctx context.Context, command *ShipOrder) (*Response, error) {
+	h.recorder.record("ship", ctx)
+	return &Response{}, nil
+}
+
+func sequentialMessageIDs(t *testing.T) {
+	next := 0
+	SetMessageIDGenerator(func() string {
+		next++
+		return fmt.Sprintf("id-%d", next)
+	})
+	t.Cleanup(func() {
+		SetMessageIDGenerator(nil)
+	})
+}
+
+func TestSend_WhenHandlersChainMessages_ShouldPropagateCorrelationAndCausation(t *testing.T) {
+	// arrange
+	defer commands_cleanup(t)
+	defer events_cleanup(t)
+	sequentialMessageIDs(t)
+	recorder := &chainRecorder{ids: make(map[string]MessageIDs)}
+	RegisterCommandHandler[*PlaceOrder, *Response](&PlaceOrderHandler{recorder: recorder})
+	RegisterCommandHandler[*ShipOrder, *Response](&ShipOrderHandler{recorder: recorder})
+	RegisterEventSubscriber[*OrderPlaced](&OrderPlacedHandler{recorder: recorder})
+
+	// act
+	_, err := Send[*PlaceOrder, *Response](context.TODO(), &PlaceOrder{})
+
+	// assert
+	assert.Nil(t, err)
+	assert.Equal(t, MessageIDs{MessageID: "id-1", CorrelationID: "id-1"}, recorder.ids["place"])
+	assert.Equal(t, MessageIDs{MessageID: "id-2", CorrelationID: "id-1", CausationID: "id-1"}, recorder.ids["placed"])
+	assert.Equal(t, MessageIDs{MessageID: "id-3", CorrelationID: "id-1", CausationID: "id-2"}, recorder.ids["ship"])
+}
+
+func TestSend_WhenCorrelationIDInContext_ShouldKeepCorrelationID(t *testing.T) {
+	// arrange
+	defer commands_cleanup(t)
+	recorder := &chainRecorder{ids: make(map[string]MessageIDs)}
+	RegisterCommandHandler[*ShipOrder, *Response](&ShipOrderHandler{recorder: recorder})
+	ctx := WithCausationID(WithCorrelationID(context.TODO(), "request-1"), "upstream-1")
+
+	// act
+	Send[*ShipOrder, *Response](ctx, &ShipOrder{})
+
+	// assert
+	ids := recorder.ids["ship"]
+	assert.NotEmpty(t, ids.MessageID)
+	assert.Equal(t, "request-1", ids.CorrelationID)
+	assert.Equal(t, "upstream-1", ids.CausationID)
+}
+
+func TestPublishEventAsync_WhenDelivered_ShouldCarryPublishMessageIDs(t *testing.T) {
+	// arrange
+	defer commands_cleanup(t)
+	defer events_cleanup(t)
+	sequentialMessageIDs(t)
+	recorder := &chainRecorder{ids: make(map[string]MessageIDs)}
+	RegisterCommandHandler[*ShipOrder, *Response](&ShipOrderHandler{recorder: recorder})
+	RegisterEventSubscriber[*OrderPlaced](&OrderPlacedHandler{recorder: recorder})
+	Listen()
+	defer StopListening(context.TODO())
+
+	// act
+	PublishEventAsync(WithCorrelationID(context.TODO(), "request-1"), &OrderPlaced{})
+
+	// assert
+	assert.Eventually(t, func() bool {
+		recorder.mutex.Lock()
+		defer recorder.mutex.Unlock()
+		_, shipped := recorder.ids["ship"]
+		return shipped
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, MessageIDs{MessageID: "id-1", CorrelationID: "request-1"}, recorder.ids["placed"])
+	assert.Equal(t, MessageIDs{MessageID: "id-2", CorrelationID: "request-1", CausationID: "id-1"}, recorder.ids["ship"])
+}
+
+func TestSend_WhenBehaviorFails_ShouldIncludeMessageIDsInError(t *testing.T) {
+	// arrange
+	defer querys_cleanup(t)
+	defer behaviors_cleanup(t)
+	defer authorization_cleanup(t)
+	sequentialMessageIDs(t)
+	RegisterQueryHandler[*Query1, *Response](&QueryHandler1{})
+	RegisterQueryPipelineBehavior(0, NewAuthorizationBehavior())
+	RegisterAuthorizationPolicy[*Query1](&AdminPolicy{})
+
+	// act
+	_, err := Request[*Query1, *Response](WithCorrelationID(context.TODO(), "request-1"), &Query1{})
+
+	// assert
+	var authorizationErr *AuthorizationError
+	assert.True(t, errors.As(err, &authorizationErr))
+	assert.Equal(t, MessageIDs{MessageID: "id-1", CorrelationID: "request-1"}, authorizationErr.MessageIDs)
+}
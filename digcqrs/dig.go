@@ -0,0 +1,28 @@
+package digcqrs
+
+import (
+	cqrs "github.com/mitz-it/golang-cqrs"
+	"go.uber.org/dig"
+)
+
+const Group = "cqrs"
+
+func Provide(container *dig.Container, constructor interface{}, options ...dig.ProvideOption) error {
+	return container.Provide(constructor, append(options, dig.Group(Group))...)
+}
+
+func ProvideAll(container *dig.Container, constructor interface{}, options ...dig.ProvideOption) error {
+	return container.Provide(constructor, append(options, dig.Group(Group+",flatten"))...)
+}
+
+type registrations struct {
+	dig.In
+
+	Registrations []cqrs.Registration `group:"cqrs"`
+}
+
+func Register(container *dig.Container) error {
+	return container.Invoke(func(in registrations) error {
+		return cqrs.Register(in.Registrations...)
+	})
+}
@@ -0,0 +1,69 @@
+package digcqrs
+
+import (
+	"context"
+	"testing"
+
+	cqrs "github.com/mitz-it/golang-cqrs"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/dig"
+)
+
+type Greeter struct {
+	greeting string
+}
+
+type Greet struct {
+	Name string
+}
+
+type GreetHandler struct {
+	greeter *Greeter
+}
+
+func (h *GreetHandler) Handle(ctx context.Context, command *Greet) (string, error) {
+	return h.greeter.greeting + " " + command.Name, nil
+}
+
+type Greeted struct {
+}
+
+type GreetedHandler struct {
+	calls int
+}
+
+func (h *GreetedHandler) Handle(ctx context.Context, event *Greeted) error {
+	h.calls++
+	return nil
+}
+
+func TestRegister_WhenRegistrationsProvided_ShouldRegisterHandlers(t *testing.T) {
+	// arrange
+	defer cqrs.Reset()
+	container := dig.New()
+	first, second := &GreetedHandler{}, &GreetedHandler{}
+	container.Provide(func() *Greeter {
+		return &Greeter{greeting: "hello"}
+	})
+	Provide(container, func(greeter *Greeter) cqrs.Registration {
+		return cqrs.CommandHandler[*Greet, string](&GreetHandler{greeter: greeter})
+	})
+	ProvideAll(container, func() []cqrs.Registration {
+		return []cqrs.Registration{
+			cqrs.EventSubscriber[*Greeted](first),
+			cqrs.EventSubscriber[*Greeted](second),
+		}
+	})
+
+	// act
+	err := Register(container)
+
+	// assert
+	res, sendErr := cqrs.Send[*Greet, string](context.TODO(), &Greet{Name: "world"})
+	assert.Nil(t, err)
+	assert.Nil(t, sendErr)
+	assert.Equal(t, "hello world", res)
+	assert.Nil(t, cqrs.PublishEvent(context.TODO(), &Greeted{}))
+	assert.Equal(t, 1, first.calls)
+	assert.Equal(t, 1, second.calls)
+}
@@ -0,0 +1,20 @@
+module github.com/mitz-it/golang-cqrs/digcqrs
+
+go 1.21
+
+require (
+	github.com/mitz-it/golang-cqrs v0.0.0-20261019102643-3d9c8458cf6f
+	github.com/stretchr/testify v1.9.0
+	go.uber.org/dig v1.18.0
+)
+
+require (
+	github.com/ahmetb/go-linq/v3 v3.2.0 // indirect
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/kr/text v0.2.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	go.uber.org/multierr v1.10.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
@@ -0,0 +1,43 @@
+package cqrs
+
+import (
+	"context"
+	"reflect"
+	"time"
+)
+
+type MessageKind string
+
+const (
+	CommandMessage MessageKind = "command"
+	QueryMessage   MessageKind = "query"
+	EventMessage   MessageKind = "event"
+)
+
+type DispatchInfo struct {
+	Kind         MessageKind
+	MessageType  string
+	HandlerType  string
+	Subscription string
+	Async        bool
+	responseType reflect.Type
+}
+
+type dispatchInfoContextKey struct{}
+
+func withDispatchInfo(ctx context.Context, info DispatchInfo) context.Context {
+	return context.WithValue(ctx, dispatchInfoContextKey{}, info)
+}
+
+func DispatchInfoFromContext(ctx context.Context) (DispatchInfo, bool) {
+	info, ok := ctx.Value(dispatchInfoContextKey{}).(DispatchInfo)
+	return info, ok
+}
+
+func dispatch(ctx context.Context, info DispatchInfo, request interface{}, behaviors map[int]interface{}, handle PipelineFunc) (res interface{}, err error) {
+	ctx = withDispatchInfo(withMessageIDs(ctx), info)
+
+	defer recordDispatch(ctx, info, time.Now(), &err)
+
+	return runPipeline(ctx, request, behaviors, handle)
+}
@@ -0,0 +1,91 @@
+package cqrs
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type InfoBehavior struct {
+	info DispatchInfo
+}
+
+func (b *InfoBehavior) Handle(ctx context.Context, request interface{}, next PipelineFunc) (interface{}, error) {
+	b.info, _ = DispatchInfoFromContext(ctx)
+	return next(ctx, request)
+}
+
+type InfoEventBehavior struct {
+	info DispatchInfo
+}
+
+func (b *InfoEventBehavior) Handle(ctx context.Context, event interface{}, handler interface{}, next EventNextFunc) error {
+	b.info, _ = DispatchInfoFromContext(ctx)
+	return next(ctx)
+}
+
+func TestDispatchInfoFromContext_WhenSendingCommand_ShouldDescribeCommand(t *testing.T) {
+	// arrange
+	defer commands_cleanup(t)
+	defer behaviors_cleanup(t)
+	behavior := &InfoBehavior{}
+	RegisterCommandHandler[*Command1, *Response](&CommandHandler1{})
+	RegisterCommandPipelineBehavior(0, behavior)
+
+	// act
+	Send[*Command1, *Response](context.TODO(), &Command1{})
+
+	// assert
+	expected := DispatchInfo{
+		Kind:        CommandMessage,
+		MessageType: "*cqrs.Command1",
+		HandlerType: "*cqrs.CommandHandler1",
+	}
+	assert.Equal(t, expected, behavior.info)
+}
+
+func TestDispatchInfoFromContext_WhenRequestingQuery_ShouldDescribeQuery(t *testing.T) {
+	// arrange
+	defer querys_cleanup(t)
+	defer behaviors_cleanup(t)
+	behavior := &InfoBehavior{}
+	RegisterQueryHandler[*Query1, *Response](&QueryHandler1{})
+	RegisterQueryPipelineBehavior(0, behavior)
+
+	// act
+	Request[*Query1, *Response](context.TODO(), &Query1{})
+
+	// assert
+	assert.Equal(t, QueryMessage, behavior.info.Kind)
+	assert.Equal(t, "*cqrs.QueryHandler1", behavior.info.HandlerType)
+}
+
+func TestDispatchInfoFromContext_WhenPublishingEvent_ShouldDescribeEvent(t *testing.T) {
+	// arrange
+	defer events_cleanup(t)
+	defer behaviors_cleanup(t)
+	behavior := &InfoEventBehavior{}
+	RegisterEventSubscriber[*FakeEvent](&FakeEventHandler1{})
+	RegisterEventBehavior(0, behavior)
+
+	// act
+	PublishEvent(context.TODO(), &FakeEvent{})
+
+	// assert
+	expected := DispatchInfo{
+		Kind:         EventMessage,
+		MessageType:  "*cqrs.FakeEvent",
+		HandlerType:  "*cqrs.FakeEventHandler1",
+		Subscription: "*cqrs.FakeEventHandler1",
+	}
+	assert.Equal(t, expected, behavior.info)
+}
+
+func TestDispatchInfoFromContext_WhenNotDispatching_ShouldReturnFalse(t *testing.T) {
+	// act
+	_, ok := DispatchInfoFromContext(context.TODO())
+
+	// assert
+	assert.False(t, ok)
+}
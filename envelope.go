@@ -0,0 +1,132 @@
+package cqrs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+type Envelope[TEvent any] struct {
+	MessageID     string
+	Timestamp     time.Time
+	CorrelationID string
+	CausationID   string
+	Headers       map[string]string
+	Attempt       int
+	Event         TEvent
+}
+
+type IEnvelopeHandler[TEvent any] interface {
+	Handle(ctx context.Context, envelope Envelope[TEvent]) error
+}
+
+func RegisterEnvelopeSubscriber[TEvent any](handler IEnvelopeHandler[TEvent]) error {
+	var event TEvent
+	eventType := reflect.TypeOf(event)
+
+	eventHandlers[eventType] = append(eventHandlers[eventType], &envelopeSubscriber[TEvent]{handler: handler})
+
+	return nil
+}
+
+type envelopeInvoker interface {
+	handleEnvelope(ctx context.Context, envelope Envelope[interface{}]) error
+}
+
+// events published as interface{} only carry their type at run time,
+// so the subscriber keeps its own type to rebuild the envelope
+type envelopeSubscriber[TEvent any] struct {
+	handler IEnvelopeHandler[TEvent]
+}
+
+func (s *envelopeSubscriber[TEvent]) handleEnvelope(ctx context.Context, envelope Envelope[interface{}]) error {
+	event, ok := envelope.Event.(TEvent)
+
+	if !ok {
+		msg := fmt.Sprintf("envelope handler of type %T can't handle event of type %T", s.handler, envelope.Event)
+		return errors.New(msg)
+	}
+
+	return s.handler.Handle(ctx, withEvent(envelope, event))
+}
+
+func (s *envelopeSubscriber[TEvent]) handlerName() string {
+	return handlerName(s.handler)
+}
+
+func withEvent[TEvent any, TOther any](envelope Envelope[TOther], event TEvent) Envelope[TEvent] {
+	return Envelope[TEvent]{
+		MessageID:     envelope.MessageID,
+		Timestamp:     envelope.Timestamp,
+		CorrelationID: envelope.CorrelationID,
+		CausationID:   envelope.CausationID,
+		Headers:       envelope.Headers,
+		Attempt:       envelope.Attempt,
+		Event:         event,
+	}
+}
+
+type headersContextKey struct{}
+
+type attemptContextKey struct{}
+
+func WithHeaders(ctx context.Context, headers map[string]string) context.Context {
+	merged := make(map[string]string)
+
+	for key, value := range HeadersFromContext(ctx) {
+		merged[key] = value
+	}
+
+	for key, value := range headers {
+		merged[key] = value
+	}
+
+	return context.WithValue(ctx, headersContextKey{}, merged)
+}
+
+func HeadersFromContext(ctx context.Context) map[string]string {
+	headers, _ := ctx.Value(headersContextKey{}).(map[string]string)
+	return headers
+}
+
+func WithDeliveryAttempt(ctx context.Context, attempt int) context.Context {
+	return context.WithValue(ctx, attemptContextKey{}, attempt)
+}
+
+func newEnvelope[TEvent any](ctx context.Context, event TEvent) Envelope[TEvent] {
+	ids := MessageIDsFromContext(ctx)
+	attempt, ok := ctx.Value(attemptContextKey{}).(int)
+
+	if !ok || attempt < 1 {
+		attempt = 1
+	}
+
+	headers := make(map[string]string)
+
+	for key, value := range HeadersFromContext(ctx) {
+		headers[key] = value
+	}
+
+	return Envelope[TEvent]{
+		MessageID:     ids.MessageID,
+		Timestamp:     time.Now(),
+		CorrelationID: ids.CorrelationID,
+		CausationID:   ids.CausationID,
+		Headers:       headers,
+		Attempt:       attempt,
+		Event:         event,
+	}
+}
+
+func invokeEnvelopeHandler[TEvent any](ctx context.Context, handler interface{}, envelope Envelope[TEvent]) error {
+	switch h := handler.(type) {
+	case envelopeInvoker:
+		return h.handleEnvelope(ctx, withEvent[interface{}](envelope, envelope.Event))
+	case IEventHandler[TEvent]:
+		return h.Handle(ctx, envelope.Event)
+	}
+
+	return invokeEventHandler(ctx, handler, envelope.Event)
+}
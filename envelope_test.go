@@ -0,0 +1,152 @@
+package cqrs
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type EnvelopeEventHandler struct {
+	envelopes chan Envelope[*FakeEvent]
+}
+
+func (h *EnvelopeEventHandler) Handle(ctx context.Context, envelope Envelope[*FakeEvent]) error {
+	h.envelopes <- envelope
+	return nil
+}
+
+type CountingFakeEventHandler struct {
+	calls int
+}
+
+func (h *CountingFakeEventHandler) Handle(ctx context.Context, event *FakeEvent) error {
+	h.calls++
+	return nil
+}
+
+type PanickingFakeEventHandler struct {
+}
+
+func (h *PanickingFakeEventHandler) Handle(ctx context.Context, event *FakeEvent) error {
+	panic("boom")
+}
+
+func TestPublishEvent_WhenEnvelopeSubscriber_ShouldReceiveEnvelope(t *testing.T) {
+	// arrange
+	defer events_cleanup(t)
+	sequentialMessageIDs(t)
+	handler := &EnvelopeEventHandler{envelopes: make(chan Envelope[*FakeEvent], 1)}
+	RegisterEnvelopeSubscriber[*FakeEvent](handler)
+	event := &FakeEvent{Message: "test"}
+	ctx := WithHeaders(WithCorrelationID(context.TODO(), "request-1"), map[string]string{"tenant": "acme"})
+	before := time.Now()
+
+	// act
+	err := PublishEvent(ctx, event)
+
+	// assert
+	envelope := <-handler.envelopes
+	assert.Nil(t, err)
+	assert.Same(t, event, envelope.Event)
+	assert.Equal(t, "id-1", envelope.MessageID)
+	assert.Equal(t, "request-1", envelope.CorrelationID)
+	assert.Equal(t, map[string]string{"tenant": "acme"}, envelope.Headers)
+	assert.Equal(t, 1, envelope.Attempt)
+	assert.False(t, envelope.Timestamp.Before(before))
+}
+
+func TestPublishEvent_WhenEnvelopeAndEventSubscribers_ShouldCallBoth(t *testing.T) {
+	// arrange
+	defer events_cleanup(t)
+	envelopeHandler := &EnvelopeEventHandler{envelopes: make(chan Envelope[*FakeEvent], 1)}
+	eventHandler := &CountingFakeEventHandler{}
+	RegisterEnvelopeSubscriber[*FakeEvent](envelopeHandler)
+	RegisterEventSubscriber[*FakeEvent](eventHandler)
+
+	// act
+	err := PublishEvent(context.TODO(), &FakeEvent{})
+
+	// assert
+	assert.Nil(t, err)
+	assert.Len(t, envelopeHandler.envelopes, 1)
+	assert.Equal(t, 1, eventHandler.calls)
+}
+
+func TestPublishEventAsync_WhenEnvelopeSubscriber_ShouldReceiveEnvelope(t *testing.T) {
+	// arrange
+	defer events_cleanup(t)
+	handler := &EnvelopeEventHandler{envelopes: make(chan Envelope[*FakeEvent], 1)}
+	RegisterEnvelopeSubscriber[*FakeEvent](handler)
+	Listen()
+	defer StopListening(context.TODO())
+	ctx := WithDeliveryAttempt(context.TODO(), 3)
+
+	// act
+	PublishEventAsync(ctx, &FakeEvent{})
+
+	// assert
+	select {
+	case envelope := <-handler.envelopes:
+		assert.NotEmpty(t, envelope.MessageID)
+		assert.Equal(t, envelope.MessageID, envelope.CorrelationID)
+		assert.Equal(t, 3, envelope.Attempt)
+	case <-time.After(time.Second):
+		t.Fatal("envelope handler was not called")
+	}
+}
+
+func TestPublishEvent_WhenEventPublishedAsInterface_ShouldReceiveEnvelope(t *testing.T) {
+	// arrange
+	defer events_cleanup(t)
+	handler := &EnvelopeEventHandler{envelopes: make(chan Envelope[*FakeEvent], 1)}
+	RegisterEnvelopeSubscriber[*FakeEvent](handler)
+	var event interface{} = &FakeEvent{Message: "test"}
+
+	// act
+	err := PublishEvent(context.TODO(), event)
+
+	// assert
+	envelope := <-handler.envelopes
+	assert.Nil(t, err)
+	assert.Same(t, event, envelope.Event)
+	assert.NotEmpty(t, envelope.MessageID)
+}
+
+func TestPublishEventAsync_WhenEventPublishedAsInterface_ShouldCallEverySubscriber(t *testing.T) {
+	// arrange
+	defer events_cleanup(t)
+	handler := &EnvelopeEventHandler{envelopes: make(chan Envelope[*FakeEvent], 1)}
+	counting := &CountingFakeEventHandler{}
+	RegisterEventSubscriber[*FakeEvent](&PanickingFakeEventHandler{})
+	RegisterEventSubscriber[*FakeEvent](counting)
+	RegisterEnvelopeSubscriber[*FakeEvent](handler)
+	Listen()
+	defer StopListening(context.TODO())
+	var event interface{} = &FakeEvent{}
+
+	// act
+	PublishEventAsync(context.TODO(), event)
+
+	// assert
+	select {
+	case envelope := <-handler.envelopes:
+		assert.Same(t, event, envelope.Event)
+	case <-time.After(time.Second):
+		t.Fatal("envelope handler was not called")
+	}
+
+	assert.Equal(t, 1, counting.calls)
+}
+
+func TestWithHeaders_WhenCalledTwice_ShouldMergeHeaders(t *testing.T) {
+	// arrange
+	ctx := WithHeaders(context.TODO(), map[string]string{"a": "1", "b": "1"})
+
+	// act
+	ctx = WithHeaders(ctx, map[string]string{"b": "2"})
+
+	// assert
+	assert.Equal(t, map[string]string{"a": "1", "b": "2"}, HeadersFromContext(ctx))
+}
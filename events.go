@@ -5,7 +5,11 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"runtime"
+	"sync"
+	"time"
 
+	"github.com/ahmetb/go-linq/v3"
 	"go.uber.org/multierr"
 )
 
@@ -17,11 +21,19 @@ type EventDelivery struct {
 	ctx       context.Context
 	eventType reflect.Type
 	event     interface{}
+	invoke    func(ctx context.Context, handler interface{}) error
 }
 
+var ErrListenerStopped = errors.New("event listener is stopped")
+
 var eventHandlers map[reflect.Type][]interface{}
 var eventListener chan *EventDelivery
 
+var listenerMutex sync.Mutex
+var listenerStop chan struct{}
+var listenerStopped bool
+var listeners sync.WaitGroup
+
 func init() {
 	eventHandlers = make(map[reflect.Type][]interface{})
 	eventListener = make(chan *EventDelivery)
@@ -44,6 +56,24 @@ func RegisterEventSubscriber[TEvent any](handler IEventHandler[TEvent]) error {
 	return nil
 }
 
+func RegisterNamedEventSubscriber[TEvent any](name string, handler IEventHandler[TEvent]) error {
+	if name == "" {
+		return errors.New("a subscription name must be provided")
+	}
+
+	var event TEvent
+	eventType := reflect.TypeOf(event)
+
+	for _, h := range eventHandlers[eventType] {
+		if named, ok := h.(namedSubscription); ok && named.subscriptionName() == name {
+			msg := fmt.Sprintf("subscription %s for event of type %s is already registered", name, eventType.String())
+			return errors.New(msg)
+		}
+	}
+
+	return RegisterEventSubscriber[TEvent](&namedSubscriber[TEvent]{name: name, handler: handler})
+}
+
 func RegisterEventSubscribers[TEvent any](handlers ...IEventHandler[TEvent]) error {
 	if len(handlers) <= 0 {
 		return errors.New("at least one handler must be provided")
@@ -67,30 +97,20 @@ func PublishEvent[TEvent any](ctx context.Context, event TEvent) error {
 
 	var err error = nil
 
-	for _, h := range handlers {
-		handler, ok := h.(IEventHandler[TEvent])
+	ctx = withMessageIDs(ctx)
+	envelope := newEnvelope(ctx, event)
+	names := subscriptionNames(handlers)
 
-		if ok {
-			handleErr := handler.Handle(ctx, event)
-
-			if handleErr != nil {
-				err = multierr.Append(err, handleErr)
-			}
-
-			continue
+	for i, h := range handlers {
+		handler := h
+		handle := func(ctx context.Context) error {
+			return invokeEnvelopeHandler(ctx, handler, envelope)
 		}
 
-		args := []reflect.Value{
-			reflect.ValueOf(ctx),
-			reflect.ValueOf(event),
-		}
-
-		r := reflect.ValueOf(h).MethodByName("Handle").Call(args)
-
-		handleErr := r[0].Interface()
+		handleErr := handleEvent(ctx, event, h, names[i], false, handle)
 
 		if handleErr != nil {
-			err = multierr.Append(err, handleErr.(error))
+			err = multierr.Append(err, handleErr)
 		}
 	}
 
@@ -99,46 +119,239 @@ func PublishEvent[TEvent any](ctx context.Context, event TEvent) error {
 
 func PublishEventAsync[TEvent any](ctx context.Context, event TEvent) error {
 	eventType := reflect.TypeOf(event)
+	ctx = withMessageIDs(ctx)
+	envelope := newEnvelope(ctx, event)
 
 	delivery := &EventDelivery{
 		ctx:       ctx,
 		eventType: eventType,
 		event:     event,
+		invoke: func(ctx context.Context, handler interface{}) error {
+			return invokeEnvelopeHandler(ctx, handler, envelope)
+		},
 	}
 
-	eventListener <- delivery
+	listenerMutex.Lock()
+	stop, stopped := listenerStop, listenerStopped
+	listenerMutex.Unlock()
+
+	if stopped {
+		return ErrListenerStopped
+	}
+
+	addAsyncQueueDepth(1)
+
+	select {
+	case eventListener <- delivery:
+	case <-stop:
+		addAsyncQueueDepth(-1)
+		return ErrListenerStopped
+	}
 
 	return nil
 }
 
 func Listen() {
-	go listen()
+	listenerMutex.Lock()
+	defer listenerMutex.Unlock()
+
+	if listenerStop == nil {
+		listenerStop = make(chan struct{})
+	}
+
+	listenerStopped = false
+	listeners.Add(1)
+
+	go listen(eventListener, listenerStop)
 }
 
-func handleRecover() {
-	if err := recover(); err != nil {
-		go listen()
+func StopListening(ctx context.Context) error {
+	listenerMutex.Lock()
+	stop := listenerStop
+	listenerStop = nil
+	listenerStopped = true
+	listenerMutex.Unlock()
+
+	if stop == nil {
+		return nil
+	}
+
+	close(stop)
+
+	done := make(chan struct{})
+
+	go func() {
+		listeners.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
 	}
 }
 
-func listen() {
-	defer handleRecover()
-	for delivery := range eventListener {
-		event := delivery.event
-		eventType := delivery.eventType
-		handlers, ok := eventHandlers[eventType]
+func handleRecover(listener chan *EventDelivery, stop chan struct{}) {
+	if err := recover(); err != nil {
+		listeners.Add(1)
+		go listen(listener, stop)
+	}
+}
 
-		if !ok {
+func listen(listener chan *EventDelivery, stop chan struct{}) {
+	defer listeners.Done()
+	addAsyncWorkers(1)
+	defer handleRecover(listener, stop)
+	defer addAsyncWorkers(-1)
+	for {
+		select {
+		case delivery := <-listener:
+			addAsyncQueueDepth(-1)
+			deliver(delivery)
+		case <-stop:
 			return
 		}
+	}
+}
+
+func deliver(delivery *EventDelivery) {
+	addAsyncBusyWorkers(1)
+	defer addAsyncBusyWorkers(-1)
+
+	event := delivery.event
+	eventType := delivery.eventType
+	handlers, ok := eventHandlers[eventType]
+
+	if !ok {
+		return
+	}
+
+	names := subscriptionNames(handlers)
+
+	for i, handler := range handlers {
+		h := handler
+		handle := func(ctx context.Context) error {
+			return delivery.invoke(ctx, h)
+		}
+		deliverTo(delivery.ctx, event, h, names[i], handle)
+	}
+}
+
+// a panicking subscriber must not keep the others from the event
+func deliverTo(ctx context.Context, event interface{}, handler interface{}, subscription string, handle EventNextFunc) {
+	defer func() {
+		recover()
+	}()
+
+	handleEvent(ctx, event, handler, subscription, true, handle)
+}
+
+func invokeEventHandler(ctx context.Context, handler interface{}, event interface{}) error {
+	args := []reflect.Value{
+		reflect.ValueOf(ctx),
+		reflect.ValueOf(event),
+	}
+
+	r := reflect.ValueOf(handler).MethodByName("Handle").Call(args)
+
+	handleErr := r[0].Interface()
+
+	if handleErr != nil {
+		return handleErr.(error)
+	}
+
+	return nil
+}
+
+func handleEvent(ctx context.Context, event interface{}, handler interface{}, subscription string, async bool, handle EventNextFunc) (err error) {
+	info := DispatchInfo{
+		Kind:         EventMessage,
+		MessageType:  reflect.TypeOf(event).String(),
+		HandlerType:  handlerName(handler),
+		Subscription: subscription,
+		Async:        async,
+	}
+
+	ctx = withDispatchInfo(ctx, info)
+
+	defer recordDispatch(ctx, info, time.Now(), &err)
+
+	if len(eventBehaviors) <= 0 {
+		return handle(ctx)
+	}
+
+	sortedBehaviors := sortBehaviors(eventBehaviors)
+
+	aggregatedPipeline := linq.From(sortedBehaviors).AggregateWithSeedT(handle, func(next EventNextFunc, b IEventBehavior) EventNextFunc {
+		var nextFunc EventNextFunc = func(ctx context.Context) error {
+			return b.Handle(ctx, event, handler, next)
+		}
+		return nextFunc
+	})
+
+	pipeline := aggregatedPipeline.(EventNextFunc)
 
-		args := []reflect.Value{
-			reflect.ValueOf(delivery.ctx),
-			reflect.ValueOf(event),
+	return pipeline(ctx)
+}
+
+type namedSubscription interface {
+	subscriptionName() string
+}
+
+type namedSubscriber[TEvent any] struct {
+	name    string
+	handler IEventHandler[TEvent]
+}
+
+func (s *namedSubscriber[TEvent]) Handle(ctx context.Context, event TEvent) error {
+	return s.handler.Handle(ctx, event)
+}
+
+func (s *namedSubscriber[TEvent]) handlerName() string {
+	return handlerName(s.handler)
+}
+
+func (s *namedSubscriber[TEvent]) subscriptionName() string {
+	return s.name
+}
+
+// unnamed subscribers sharing a handler name are told apart by their registration
+// order, the first one keeps the plain name so existing inbox records stay valid
+func subscriptionNames(handlers []interface{}) []string {
+	names := make([]string, len(handlers))
+	seen := make(map[string]int)
+
+	for i, handler := range handlers {
+		if named, ok := handler.(namedSubscription); ok {
+			names[i] = named.subscriptionName()
+			continue
 		}
 
-		for _, handler := range handlers {
-			reflect.ValueOf(handler).MethodByName("Handle").Call(args)
+		name := handlerName(handler)
+		seen[name]++
+
+		if seen[name] > 1 {
+			name = fmt.Sprintf("%s#%d", name, seen[name])
 		}
+
+		names[i] = name
+	}
+
+	return names
+}
+
+func handlerName(handler interface{}) string {
+	if named, ok := handler.(namedHandler); ok {
+		return named.handlerName()
+	}
+
+	value := reflect.ValueOf(handler)
+
+	if value.Kind() == reflect.Func && !value.IsNil() {
+		return runtime.FuncForPC(value.Pointer()).Name()
 	}
+
+	return reflect.TypeOf(handler).String()
 }
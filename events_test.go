@@ -5,6 +5,7 @@ import (
 	"errors"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -110,6 +111,26 @@ func TestRegisterEventSubscribers_WhenNotAnyHandler_ShouldReturnError(t *testing
 	assert.NotNil(t, err)
 }
 
+func TestRegisterNamedEventSubscriber_WhenNameAlreadyRegistered_ShouldReturnError(t *testing.T) {
+	// arrange
+	defer events_cleanup(t)
+	RegisterNamedEventSubscriber[*FakeEvent]("audit", &FakeEventHandler1{})
+
+	// act
+	err := RegisterNamedEventSubscriber[*FakeEvent]("audit", &FakeEventHandler2{})
+
+	// assert
+	assert.EqualError(t, err, "subscription audit for event of type *cqrs.FakeEvent is already registered")
+}
+
+func TestRegisterNamedEventSubscriber_WhenNameIsEmpty_ShouldReturnError(t *testing.T) {
+	// act
+	err := RegisterNamedEventSubscriber[*FakeEvent]("", &FakeEventHandler1{})
+
+	// assert
+	assert.Error(t, err)
+}
+
 func TestPublishEvent_WhenEventHandlerNotFound_ShouldReturn(t *testing.T) {
 	// arrange
 	defer events_cleanup(t)
@@ -196,6 +217,7 @@ func TestPublishEventAsync_WhenListening_ShouldHandleEvent(t *testing.T) {
 	handler := &FakeEventHandler1{}
 	RegisterEventSubscriber[*FakeEvent](handler)
 	Listen()
+	defer StopListening(context.TODO())
 
 	// act
 	publish := func() {
@@ -215,6 +237,7 @@ func TestPublishEventAsync_WhenHandlerNotFound_ShouldReturnEarlyOnDelivery(t *te
 		Message: "test",
 	}
 	Listen()
+	defer StopListening(context.TODO())
 
 	// act
 	publish := func() {
@@ -234,6 +257,7 @@ func TestPublishEventAsync_WhenHandlerPanic_ShouldRecover(t *testing.T) {
 	handler := &FakeEventHandler3{}
 	RegisterEventSubscriber[*FakeEvent](handler)
 	Listen()
+	defer StopListening(context.TODO())
 
 	// act
 	publish := func() {
@@ -243,3 +267,73 @@ func TestPublishEventAsync_WhenHandlerPanic_ShouldRecover(t *testing.T) {
 	// assert
 	assert.NotPanics(t, publish)
 }
+
+type RecordingEventBehavior struct {
+	handlers []interface{}
+}
+
+func (b *RecordingEventBehavior) Handle(ctx context.Context, event interface{}, handler interface{}, next EventNextFunc) error {
+	b.handlers = append(b.handlers, handler)
+	return next(ctx)
+}
+
+func TestPublishEvent_WhenHaveEventBehaviors_ShouldCallEachHandlerThroughPipeline(t *testing.T) {
+	// arrange
+	defer events_cleanup(t)
+	defer behaviors_cleanup(t)
+	event := &FakeEvent{
+		Message: "test",
+	}
+	handler1 := &FakeEventHandler1{}
+	handler2 := &FakeEventHandler2{}
+	behavior := &RecordingEventBehavior{}
+	RegisterEventSubscribers[*FakeEvent](handler1, handler2)
+	RegisterEventBehavior(0, behavior)
+
+	// act
+	err := PublishEvent(context.TODO(), event)
+
+	// assert
+	assert.NotNil(t, err)
+	assert.Equal(t, []interface{}{handler1, handler2}, behavior.handlers)
+}
+
+func TestStopListening_WhenListening_ShouldStopListenersAndRejectEvents(t *testing.T) {
+	// arrange
+	defer events_cleanup(t)
+	handler := &CountingFakeEventHandler{}
+	RegisterEventSubscriber[*FakeEvent](handler)
+	Listen()
+	PublishEventAsync(context.TODO(), &FakeEvent{})
+
+	// act
+	err := StopListening(context.TODO())
+	publishErr := PublishEventAsync(context.TODO(), &FakeEvent{})
+
+	// assert
+	assert.Nil(t, err)
+	assert.ErrorIs(t, publishErr, ErrListenerStopped)
+	assert.Equal(t, 1, handler.calls)
+}
+
+func TestListen_WhenStartedAfterStop_ShouldHandleEventsAgain(t *testing.T) {
+	// arrange
+	defer events_cleanup(t)
+	handler := &EnvelopeEventHandler{envelopes: make(chan Envelope[*FakeEvent], 1)}
+	RegisterEnvelopeSubscriber[*FakeEvent](handler)
+	Listen()
+	StopListening(context.TODO())
+
+	// act
+	Listen()
+	defer StopListening(context.TODO())
+	err := PublishEventAsync(context.TODO(), &FakeEvent{})
+
+	// assert
+	assert.Nil(t, err)
+	select {
+	case <-handler.envelopes:
+	case <-time.After(time.Second):
+		t.Fatal("event was not handled")
+	}
+}
@@ -0,0 +1,173 @@
+package cqrs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime"
+
+	"go.uber.org/multierr"
+)
+
+type Disposable interface {
+	Dispose(ctx context.Context) error
+}
+
+type CommandHandlerFactory[TCommand any, TResponse any] func(ctx context.Context) (ICommandHandler[TCommand, TResponse], error)
+
+type VoidCommandHandlerFactory[TCommand any] func(ctx context.Context) (IVoidCommandHandler[TCommand], error)
+
+type QueryHandlerFactory[TQuery any, TResponse any] func(ctx context.Context) (IQueryHandler[TQuery, TResponse], error)
+
+type EventHandlerFactory[TEvent any] func(ctx context.Context) (IEventHandler[TEvent], error)
+
+func RegisterCommandHandlerFactory[TCommand any, TResponse any](factory CommandHandlerFactory[TCommand, TResponse]) error {
+	return RegisterCommandHandler[TCommand, TResponse](&commandHandlerFactory[TCommand, TResponse]{factory: factory})
+}
+
+func RegisterVoidCommandHandlerFactory[TCommand any](factory VoidCommandHandlerFactory[TCommand]) error {
+	return RegisterVoidCommandHandler[TCommand](&voidCommandHandlerFactory[TCommand]{factory: factory})
+}
+
+func RegisterQueryHandlerFactory[TQuery any, TResponse any](factory QueryHandlerFactory[TQuery, TResponse]) error {
+	return RegisterQueryHandler[TQuery, TResponse](&queryHandlerFactory[TQuery, TResponse]{factory: factory})
+}
+
+func RegisterEventSubscriberFactory[TEvent any](factory EventHandlerFactory[TEvent]) error {
+	return RegisterEventSubscriber[TEvent](&eventHandlerFactory[TEvent]{factory: factory})
+}
+
+type namedHandler interface {
+	handlerName() string
+}
+
+type commandHandlerFactory[TCommand any, TResponse any] struct {
+	factory CommandHandlerFactory[TCommand, TResponse]
+}
+
+func (f *commandHandlerFactory[TCommand, TResponse]) Handle(ctx context.Context, command TCommand) (res TResponse, err error) {
+	handler, release, err := resolve(ctx, f.factory)
+
+	if err != nil {
+		return res, err
+	}
+
+	defer release(&err)
+
+	return handler.Handle(ctx, command)
+}
+
+func (f *commandHandlerFactory[TCommand, TResponse]) handlerName() string {
+	return factoryName(f.factory)
+}
+
+type voidCommandHandlerFactory[TCommand any] struct {
+	factory VoidCommandHandlerFactory[TCommand]
+}
+
+func (f *voidCommandHandlerFactory[TCommand]) Handle(ctx context.Context, command TCommand) (err error) {
+	handler, release, err := resolve(ctx, f.factory)
+
+	if err != nil {
+		return err
+	}
+
+	defer release(&err)
+
+	return handler.Handle(ctx, command)
+}
+
+func (f *voidCommandHandlerFactory[TCommand]) handlerName() string {
+	return factoryName(f.factory)
+}
+
+type queryHandlerFactory[TQuery any, TResponse any] struct {
+	factory QueryHandlerFactory[TQuery, TResponse]
+}
+
+func (f *queryHandlerFactory[TQuery, TResponse]) Handle(ctx context.Context, query TQuery) (res TResponse, err error) {
+	handler, release, err := resolve(ctx, f.factory)
+
+	if err != nil {
+		return res, err
+	}
+
+	defer release(&err)
+
+	return handler.Handle(ctx, query)
+}
+
+func (f *queryHandlerFactory[TQuery, TResponse]) handlerName() string {
+	return factoryName(f.factory)
+}
+
+type eventHandlerFactory[TEvent any] struct {
+	factory EventHandlerFactory[TEvent]
+}
+
+func (f *eventHandlerFactory[TEvent]) Handle(ctx context.Context, event TEvent) (err error) {
+	handler, release, err := resolve(ctx, f.factory)
+
+	if err != nil {
+		return err
+	}
+
+	defer release(&err)
+
+	return handler.Handle(ctx, event)
+}
+
+func (f *eventHandlerFactory[TEvent]) handlerName() string {
+	return factoryName(f.factory)
+}
+
+// resolve creates the handler for a single call, release disposes of it and
+// appends the dispose error to the call's error
+func resolve[THandler any](ctx context.Context, factory func(ctx context.Context) (THandler, error)) (THandler, func(err *error), error) {
+	handler, err := factory(ctx)
+
+	if err == nil && isNilHandler(handler) {
+		err = noHandlerCreated(factory)
+	}
+
+	if err != nil {
+		var none THandler
+		return none, nil, err
+	}
+
+	release := func(err *error) {
+		dispose(ctx, handler, err)
+	}
+
+	return handler, release, nil
+}
+
+func dispose(ctx context.Context, handler interface{}, err *error) {
+	disposable, ok := handler.(Disposable)
+
+	if !ok {
+		return
+	}
+
+	*err = multierr.Append(*err, disposable.Dispose(ctx))
+}
+
+func isNilHandler(handler interface{}) bool {
+	if handler == nil {
+		return true
+	}
+
+	value := reflect.ValueOf(handler)
+
+	return value.Kind() == reflect.Pointer && value.IsNil()
+}
+
+func noHandlerCreated(factory interface{}) error {
+	msg := fmt.Sprintf("handler factory %s returned no handler", factoryName(factory))
+	return errors.New(msg)
+}
+
+func factoryName(factory interface{}) string {
+	return runtime.FuncForPC(reflect.ValueOf(factory).Pointer()).Name()
+}
@@ -0,0 +1,169 @@
+package cqrs
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type ScopedCommandHandler struct {
+	tenant     string
+	disposed   *[]string
+	disposeErr error
+}
+
+func (h *ScopedCommandHandler) Handle(ctx context.Context, command *Command1) (*Response, error) {
+	return &Response{}, nil
+}
+
+func (h *ScopedCommandHandler) Dispose(ctx context.Context) error {
+	*h.disposed = append(*h.disposed, h.tenant)
+	return h.disposeErr
+}
+
+type ScopedEventHandler struct {
+	calls *int
+}
+
+func (h *ScopedEventHandler) Handle(ctx context.Context, event *FakeEvent) error {
+	*h.calls++
+	return nil
+}
+
+func TestRegisterCommandHandlerFactory_WhenCommandSent_ShouldCreateAndDisposeHandlerPerDispatch(t *testing.T) {
+	// arrange
+	defer commands_cleanup(t)
+	disposed := []string{}
+	RegisterCommandHandlerFactory(func(ctx context.Context) (ICommandHandler[*Command1, *Response], error) {
+		tenant, _ := ctx.Value(tenantContextKey{}).(string)
+		return &ScopedCommandHandler{tenant: tenant, disposed: &disposed}, nil
+	})
+
+	// act
+	Send[*Command1, *Response](context.WithValue(context.TODO(), tenantContextKey{}, "a"), &Command1{})
+	res, err := Send[*Command1, *Response](context.WithValue(context.TODO(), tenantContextKey{}, "b"), &Command1{})
+
+	// assert
+	assert.Nil(t, err)
+	assert.Equal(t, &Response{}, res)
+	assert.Equal(t, []string{"a", "b"}, disposed)
+}
+
+func TestRegisterCommandHandlerFactory_WhenFactoryFails_ShouldReturnError(t *testing.T) {
+	// arrange
+	defer commands_cleanup(t)
+	RegisterCommandHandlerFactory(func(ctx context.Context) (ICommandHandler[*Command1, *Response], error) {
+		return nil, errors.New("no connection")
+	})
+
+	// act
+	_, err := Send[*Command1, *Response](context.TODO(), &Command1{})
+
+	// assert
+	assert.EqualError(t, err, "no connection")
+}
+
+func TestRegisterCommandHandlerFactory_WhenFactoryReturnsNil_ShouldReturnError(t *testing.T) {
+	// arrange
+	defer commands_cleanup(t)
+	RegisterCommandHandlerFactory(func(ctx context.Context) (ICommandHandler[*Command1, *Response], error) {
+		var handler *ScopedCommandHandler
+		return handler, nil
+	})
+
+	// act
+	_, err := Send[*Command1, *Response](context.TODO(), &Command1{})
+
+	// assert
+	assert.Error(t, err)
+	assert.True(t, strings.HasSuffix(err.Error(), "returned no handler"))
+}
+
+func TestRegisterCommandHandlerFactory_WhenDisposeFails_ShouldReturnError(t *testing.T) {
+	// arrange
+	defer commands_cleanup(t)
+	disposed := []string{}
+	RegisterCommandHandlerFactory(func(ctx context.Context) (ICommandHandler[*Command1, *Response], error) {
+		return &ScopedCommandHandler{disposed: &disposed, disposeErr: errors.New("close failed")}, nil
+	})
+
+	// act
+	_, err := Send[*Command1, *Response](context.TODO(), &Command1{})
+
+	// assert
+	assert.EqualError(t, err, "close failed")
+}
+
+func TestRegisterCommandHandlerFactory_WhenDispatched_ShouldNameHandlerAfterFactory(t *testing.T) {
+	// arrange
+	defer commands_cleanup(t)
+	defer behaviors_cleanup(t)
+	var info DispatchInfo
+	RegisterCommandHandlerFactory(func(ctx context.Context) (ICommandHandler[*Command1, *Response], error) {
+		return &CommandHandler1{}, nil
+	})
+	RegisterCommandPipelineBehavior(0, BehaviorFunc(func(ctx context.Context, request interface{}, next PipelineFunc) (interface{}, error) {
+		info, _ = DispatchInfoFromContext(ctx)
+		return next(ctx, request)
+	}))
+
+	// act
+	Send[*Command1, *Response](context.TODO(), &Command1{})
+
+	// assert
+	assert.True(t, strings.HasPrefix(info.HandlerType, "github.com/mitz-it/golang-cqrs.TestRegisterCommandHandlerFactory_WhenDispatched_ShouldNameHandlerAfterFactory"))
+}
+
+func TestRegisterVoidCommandHandlerFactory_WhenCommandSent_ShouldCreateHandler(t *testing.T) {
+	// arrange
+	defer commands_cleanup(t)
+	handler := &VoidCommandHandler1{}
+	RegisterVoidCommandHandlerFactory(func(ctx context.Context) (IVoidCommandHandler[*Command1], error) {
+		return handler, nil
+	})
+
+	// act
+	err := SendVoid(context.TODO(), &Command1{})
+
+	// assert
+	assert.Nil(t, err)
+	assert.Equal(t, 1, handler.calls)
+}
+
+func TestRegisterQueryHandlerFactory_WhenQueryRequested_ShouldCreateHandler(t *testing.T) {
+	// arrange
+	defer querys_cleanup(t)
+	created := 0
+	RegisterQueryHandlerFactory(func(ctx context.Context) (IQueryHandler[*Query1, *Response], error) {
+		created++
+		return &QueryHandler1{}, nil
+	})
+
+	// act
+	Request[*Query1, *Response](context.TODO(), &Query1{})
+	res, err := Request[*Query1, *Response](context.TODO(), &Query1{})
+
+	// assert
+	assert.Nil(t, err)
+	assert.NotNil(t, res)
+	assert.Equal(t, 2, created)
+}
+
+func TestRegisterEventSubscriberFactory_WhenEventPublished_ShouldCreateHandler(t *testing.T) {
+	// arrange
+	defer events_cleanup(t)
+	calls := 0
+	RegisterEventSubscriberFactory(func(ctx context.Context) (IEventHandler[*FakeEvent], error) {
+		return &ScopedEventHandler{calls: &calls}, nil
+	})
+
+	// act
+	err := PublishEvent(context.TODO(), &FakeEvent{})
+
+	// assert
+	assert.Nil(t, err)
+	assert.Equal(t, 1, calls)
+}
@@ -0,0 +1,57 @@
+package cqrs
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+type flightCall struct {
+	done     chan struct{}
+	response interface{}
+	err      error
+}
+
+type flightGroup struct {
+	mutex sync.Mutex
+	calls map[string]*flightCall
+}
+
+func newFlightGroup() *flightGroup {
+	return &flightGroup{
+		calls: make(map[string]*flightCall),
+	}
+}
+
+func (g *flightGroup) do(ctx context.Context, key string, fn func() (interface{}, error)) (interface{}, error) {
+	g.mutex.Lock()
+
+	if call, found := g.calls[key]; found {
+		g.mutex.Unlock()
+
+		select {
+		case <-call.done:
+			return call.response, call.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	call := &flightCall{
+		done: make(chan struct{}),
+		err:  fmt.Errorf("call for key %s did not complete", key),
+	}
+	g.calls[key] = call
+	g.mutex.Unlock()
+
+	defer func() {
+		g.mutex.Lock()
+		delete(g.calls, key)
+		g.mutex.Unlock()
+		close(call.done)
+	}()
+
+	call.response, call.err = fn()
+
+	return call.response, call.err
+}
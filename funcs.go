@@ -0,0 +1,55 @@
+package cqrs
+
+import "context"
+
+type CommandHandlerFunc[TCommand any, TResponse any] func(ctx context.Context, command TCommand) (TResponse, error)
+
+func (f CommandHandlerFunc[TCommand, TResponse]) Handle(ctx context.Context, command TCommand) (TResponse, error) {
+	return f(ctx, command)
+}
+
+func RegisterCommandHandlerFunc[TCommand any, TResponse any](handler func(ctx context.Context, command TCommand) (TResponse, error)) error {
+	return RegisterCommandHandler[TCommand, TResponse](CommandHandlerFunc[TCommand, TResponse](handler))
+}
+
+type VoidCommandHandlerFunc[TCommand any] func(ctx context.Context, command TCommand) error
+
+func (f VoidCommandHandlerFunc[TCommand]) Handle(ctx context.Context, command TCommand) error {
+	return f(ctx, command)
+}
+
+func RegisterVoidCommandHandlerFunc[TCommand any](handler func(ctx context.Context, command TCommand) error) error {
+	return RegisterVoidCommandHandler[TCommand](VoidCommandHandlerFunc[TCommand](handler))
+}
+
+type QueryHandlerFunc[TQuery any, TResponse any] func(ctx context.Context, query TQuery) (TResponse, error)
+
+func (f QueryHandlerFunc[TQuery, TResponse]) Handle(ctx context.Context, query TQuery) (TResponse, error) {
+	return f(ctx, query)
+}
+
+func RegisterQueryHandlerFunc[TQuery any, TResponse any](handler func(ctx context.Context, query TQuery) (TResponse, error)) error {
+	return RegisterQueryHandler[TQuery, TResponse](QueryHandlerFunc[TQuery, TResponse](handler))
+}
+
+type EventHandlerFunc[TEvent any] func(ctx context.Context, event TEvent) error
+
+func (f EventHandlerFunc[TEvent]) Handle(ctx context.Context, event TEvent) error {
+	return f(ctx, event)
+}
+
+func SubscribeFunc[TEvent any](handler func(ctx context.Context, event TEvent) error) error {
+	return RegisterEventSubscriber[TEvent](EventHandlerFunc[TEvent](handler))
+}
+
+type BehaviorFunc func(ctx context.Context, request interface{}, next PipelineFunc) (interface{}, error)
+
+func (f BehaviorFunc) Handle(ctx context.Context, request interface{}, next PipelineFunc) (interface{}, error) {
+	return f(ctx, request, next)
+}
+
+type EventBehaviorFunc func(ctx context.Context, event interface{}, handler interface{}, next EventNextFunc) error
+
+func (f EventBehaviorFunc) Handle(ctx context.Context, event interface{}, handler interface{}, next EventNextFunc) error {
+	return f(ctx, event, handler, next)
+}
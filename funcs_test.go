@@ -0,0 +1,132 @@
+package cqrs
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegisterCommandHandlerFunc_WhenCommandSent_ShouldCallFunc(t *testing.T) {
+	// arrange
+	defer commands_cleanup(t)
+	RegisterCommandHandlerFunc(func(ctx context.Context, command *Command1) (*Response, error) {
+		return &Response{}, nil
+	})
+
+	// act
+	res, err := Send[*Command1, *Response](context.TODO(), &Command1{})
+
+	// assert
+	assert.Nil(t, err)
+	assert.Equal(t, &Response{}, res)
+}
+
+func TestRegisterCommandHandlerFunc_WhenAlreadyRegistered_ShouldReturnError(t *testing.T) {
+	// arrange
+	defer commands_cleanup(t)
+	RegisterCommandHandler[*Command1, *Response](&CommandHandler1{})
+
+	// act
+	err := RegisterCommandHandlerFunc(func(ctx context.Context, command *Command1) (*Response, error) {
+		return nil, nil
+	})
+
+	// assert
+	assert.Error(t, err)
+}
+
+func TestRegisterVoidCommandHandlerFunc_WhenCommandSent_ShouldCallFunc(t *testing.T) {
+	// arrange
+	defer commands_cleanup(t)
+	calls := 0
+	RegisterVoidCommandHandlerFunc(func(ctx context.Context, command *Command1) error {
+		calls++
+		return nil
+	})
+
+	// act
+	err := SendVoid(context.TODO(), &Command1{})
+
+	// assert
+	assert.Nil(t, err)
+	assert.Equal(t, 1, calls)
+}
+
+func TestRegisterQueryHandlerFunc_WhenQueryRequested_ShouldCallFunc(t *testing.T) {
+	// arrange
+	defer querys_cleanup(t)
+	RegisterQueryHandlerFunc(func(ctx context.Context, query *Query1) (*Response, error) {
+		return &Response{}, nil
+	})
+
+	// act
+	res, err := Request[*Query1, *Response](context.TODO(), &Query1{})
+
+	// assert
+	assert.Nil(t, err)
+	assert.Equal(t, &Response{}, res)
+}
+
+func TestSubscribeFunc_WhenEventPublished_ShouldCallEachFunc(t *testing.T) {
+	// arrange
+	defer events_cleanup(t)
+	defer behaviors_cleanup(t)
+	behavior := &RecordingEventBehavior{}
+	calls := []string{}
+	SubscribeFunc(func(ctx context.Context, event *FakeEvent) error {
+		calls = append(calls, "first")
+		return nil
+	})
+	SubscribeFunc(func(ctx context.Context, event *FakeEvent) error {
+		calls = append(calls, "second")
+		return nil
+	})
+	RegisterEventBehavior(0, behavior)
+
+	// act
+	err := PublishEvent(context.TODO(), &FakeEvent{})
+
+	// assert
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"first", "second"}, calls)
+	assert.NotEqual(t, handlerName(behavior.handlers[0]), handlerName(behavior.handlers[1]))
+}
+
+func TestBehaviorFunc_WhenRegistered_ShouldWrapHandler(t *testing.T) {
+	// arrange
+	defer commands_cleanup(t)
+	defer behaviors_cleanup(t)
+	RegisterCommandHandler[*Command1, *Response](&CommandHandler1{})
+	calls := 0
+	RegisterCommandPipelineBehavior(0, BehaviorFunc(func(ctx context.Context, request interface{}, next PipelineFunc) (interface{}, error) {
+		calls++
+		return next(ctx, request)
+	}))
+
+	// act
+	res, err := Send[*Command1, *Response](context.TODO(), &Command1{})
+
+	// assert
+	assert.Nil(t, err)
+	assert.NotNil(t, res)
+	assert.Equal(t, 1, calls)
+}
+
+func TestEventBehaviorFunc_WhenRegistered_ShouldWrapHandler(t *testing.T) {
+	// arrange
+	defer events_cleanup(t)
+	defer behaviors_cleanup(t)
+	RegisterEventSubscriber[*FakeEvent](&FakeEventHandler1{})
+	calls := 0
+	RegisterEventBehavior(0, EventBehaviorFunc(func(ctx context.Context, event interface{}, handler interface{}, next EventNextFunc) error {
+		calls++
+		return next(ctx)
+	}))
+
+	// act
+	PublishEvent(context.TODO(), &FakeEvent{})
+
+	// assert
+	assert.Equal(t, 1, calls)
+}
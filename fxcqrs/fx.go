@@ -0,0 +1,55 @@
+package fxcqrs
+
+import (
+	"context"
+
+	cqrs "github.com/mitz-it/golang-cqrs"
+	"go.uber.org/fx"
+)
+
+const Group = "cqrs"
+
+var Module = fx.Module("cqrs",
+	fx.Invoke(fx.Annotate(register, fx.ParamTags(``, `group:"cqrs"`, `optional:"true"`))),
+)
+
+type resetOnStop bool
+
+func ResetOnStop() fx.Option {
+	return fx.Supply(resetOnStop(true))
+}
+
+func Provide(constructor interface{}) fx.Option {
+	return fx.Provide(fx.Annotate(constructor, fx.ResultTags(`group:"cqrs"`)))
+}
+
+func ProvideAll(constructor interface{}) fx.Option {
+	return fx.Provide(fx.Annotate(constructor, fx.ResultTags(`group:"cqrs,flatten"`)))
+}
+
+func register(lifecycle fx.Lifecycle, registrations []cqrs.Registration, reset resetOnStop) error {
+	err := cqrs.Register(registrations...)
+
+	if err != nil {
+		return err
+	}
+
+	lifecycle.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			cqrs.Listen()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			err := cqrs.StopListening(ctx)
+
+			// listeners that didn't stop in time may still read the registries
+			if err == nil && reset {
+				cqrs.Reset()
+			}
+
+			return err
+		},
+	})
+
+	return nil
+}
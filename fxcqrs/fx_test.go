@@ -0,0 +1,122 @@
+package fxcqrs
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	cqrs "github.com/mitz-it/golang-cqrs"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+)
+
+type Greeter struct {
+	greeting string
+}
+
+type Greet struct {
+	Name string
+}
+
+type GreetHandler struct {
+	greeter *Greeter
+}
+
+func (h *GreetHandler) Handle(ctx context.Context, command *Greet) (string, error) {
+	return h.greeter.greeting + " " + command.Name, nil
+}
+
+type Greeted struct {
+}
+
+type GreetedHandler struct {
+	handled chan struct{}
+}
+
+func (h *GreetedHandler) Handle(ctx context.Context, event *Greeted) error {
+	h.handled <- struct{}{}
+	return nil
+}
+
+func TestModule_WhenAppStarts_ShouldRegisterHandlersAndListen(t *testing.T) {
+	// arrange
+	defer cqrs.Reset()
+	handler := &GreetedHandler{handled: make(chan struct{}, 1)}
+	app := fxtest.New(t,
+		Module,
+		fx.Provide(func() *Greeter {
+			return &Greeter{greeting: "hello"}
+		}),
+		Provide(func(greeter *Greeter) cqrs.Registration {
+			return cqrs.CommandHandler[*Greet, string](&GreetHandler{greeter: greeter})
+		}),
+		ProvideAll(func() []cqrs.Registration {
+			return []cqrs.Registration{cqrs.EventSubscriber[*Greeted](handler)}
+		}),
+	)
+
+	// act
+	app.RequireStart()
+	res, err := cqrs.Send[*Greet, string](context.TODO(), &Greet{Name: "world"})
+	publishErr := cqrs.PublishEventAsync(context.TODO(), &Greeted{})
+
+	// assert
+	assert.Nil(t, err)
+	assert.Equal(t, "hello world", res)
+	assert.Nil(t, publishErr)
+	select {
+	case <-handler.handled:
+	case <-time.After(time.Second):
+		t.Fatal("event was not handled")
+	}
+
+	app.RequireStop()
+	assert.ErrorIs(t, cqrs.PublishEventAsync(context.TODO(), &Greeted{}), cqrs.ErrListenerStopped)
+}
+
+func TestModule_WhenAppRestarted_ShouldRegisterHandlersAgain(t *testing.T) {
+	// arrange
+	newApp := func() *fxtest.App {
+		return fxtest.New(t,
+			Module,
+			ResetOnStop(),
+			Provide(func() cqrs.Registration {
+				return cqrs.CommandHandler[*Greet, string](&GreetHandler{greeter: &Greeter{greeting: "hi"}})
+			}),
+		)
+	}
+	first := newApp()
+	first.RequireStart()
+	first.RequireStop()
+
+	// act
+	second := newApp()
+	second.RequireStart()
+	defer second.RequireStop()
+	res, err := cqrs.Send[*Greet, string](context.TODO(), &Greet{Name: "again"})
+
+	// assert
+	assert.Nil(t, err)
+	assert.Equal(t, "hi again", res)
+}
+
+func TestModule_WhenAppStopsWithoutResetOnStop_ShouldKeepHandlers(t *testing.T) {
+	// arrange
+	defer cqrs.Reset()
+	app := fxtest.New(t,
+		Module,
+		Provide(func() cqrs.Registration {
+			return cqrs.CommandHandler[*Greet, string](&GreetHandler{greeter: &Greeter{greeting: "hi"}})
+		}),
+	)
+	app.RequireStart()
+
+	// act
+	app.RequireStop()
+	res, err := cqrs.Send[*Greet, string](context.TODO(), &Greet{Name: "there"})
+
+	// assert
+	assert.Nil(t, err)
+	assert.Equal(t, "hi there", res)
+}
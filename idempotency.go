@@ -0,0 +1,182 @@
+package cqrs
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+type Idempotent interface {
+	IdempotencyKey() string
+}
+
+type idempotencyKeyContextKey struct{}
+
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKeyContextKey{}, key)
+}
+
+func IdempotencyKeyFromContext(ctx context.Context) (string, bool) {
+	key, ok := ctx.Value(idempotencyKeyContextKey{}).(string)
+	return key, ok && key != ""
+}
+
+type IdempotencyStore interface {
+	Get(ctx context.Context, key string) (interface{}, bool, error)
+	Set(ctx context.Context, key string, response interface{}, ttl time.Duration) error
+}
+
+type IdempotencyErrorHook func(ctx context.Context, key string, err error)
+
+type IdempotencyOption func(behavior *IdempotencyBehavior)
+
+func OnIdempotencyError(hook IdempotencyErrorHook) IdempotencyOption {
+	return func(behavior *IdempotencyBehavior) {
+		behavior.onError = hook
+	}
+}
+
+type IdempotencyBehavior struct {
+	store   IdempotencyStore
+	ttl     time.Duration
+	flights *flightGroup
+	onError IdempotencyErrorHook
+}
+
+func NewIdempotencyBehavior(store IdempotencyStore, ttl time.Duration, options ...IdempotencyOption) *IdempotencyBehavior {
+	behavior := &IdempotencyBehavior{
+		store:   store,
+		ttl:     ttl,
+		flights: newFlightGroup(),
+	}
+
+	for _, option := range options {
+		option(behavior)
+	}
+
+	return behavior
+}
+
+func (b *IdempotencyBehavior) Handle(ctx context.Context, request interface{}, next PipelineFunc) (interface{}, error) {
+	key, ok := idempotencyKey(ctx, request)
+
+	if !ok {
+		return next(ctx, request)
+	}
+
+	key = fmt.Sprintf("%T:%s", request, key)
+
+	return b.flights.do(ctx, key, func() (interface{}, error) {
+		cached, found, err := b.store.Get(ctx, key)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if found {
+			return cached, nil
+		}
+
+		res, err := next(ctx, request)
+
+		if err != nil {
+			return res, err
+		}
+
+		// the command already ran, failing here would make the client retry it
+		if err := b.store.Set(ctx, key, res, b.ttl); err != nil && b.onError != nil {
+			b.onError(ctx, key, err)
+		}
+
+		return res, nil
+	})
+}
+
+func (b *IdempotencyBehavior) CheckBatchItem(ctx context.Context, request interface{}) error {
+	if idempotent, ok := request.(Idempotent); ok && idempotent.IdempotencyKey() != "" {
+		return batchNotSupported(request, b)
+	}
+
+	return nil
+}
+
+func idempotencyKey(ctx context.Context, request interface{}) (string, bool) {
+	idempotent, ok := request.(Idempotent)
+
+	if ok && idempotent.IdempotencyKey() != "" {
+		return idempotent.IdempotencyKey(), true
+	}
+
+	return IdempotencyKeyFromContext(ctx)
+}
+
+type idempotencyEntry struct {
+	response  interface{}
+	expiresAt time.Time
+}
+
+type MemoryIdempotencyStore struct {
+	mutex     sync.Mutex
+	entries   map[string]idempotencyEntry
+	now       func() time.Time
+	lastSweep time.Time
+}
+
+func NewMemoryIdempotencyStore() *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{
+		entries: make(map[string]idempotencyEntry),
+		now:     time.Now,
+	}
+}
+
+func (s *MemoryIdempotencyStore) Get(ctx context.Context, key string) (interface{}, bool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	entry, found := s.entries[key]
+
+	if !found {
+		return nil, false, nil
+	}
+
+	if !entry.expiresAt.IsZero() && !s.now().Before(entry.expiresAt) {
+		delete(s.entries, key)
+		return nil, false, nil
+	}
+
+	return entry.response, true, nil
+}
+
+func (s *MemoryIdempotencyStore) Set(ctx context.Context, key string, response interface{}, ttl time.Duration) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := s.now()
+
+	if now.Sub(s.lastSweep) >= time.Minute {
+		s.sweep(now)
+	}
+
+	entry := idempotencyEntry{
+		response: response,
+	}
+
+	if ttl > 0 {
+		entry.expiresAt = now.Add(ttl)
+	}
+
+	s.entries[key] = entry
+
+	return nil
+}
+
+func (s *MemoryIdempotencyStore) sweep(now time.Time) {
+	for key, entry := range s.entries {
+		if !entry.expiresAt.IsZero() && !now.Before(entry.expiresAt) {
+			delete(s.entries, key)
+		}
+	}
+
+	s.lastSweep = now
+}
@@ -0,0 +1,190 @@
+package cqrs
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type IdempotentCommand struct {
+	Key string
+}
+
+func (c *IdempotentCommand) IdempotencyKey() string {
+	return c.Key
+}
+
+type IdempotentCommandHandler struct {
+	calls int32
+	delay time.Duration
+}
+
+func (h *IdempotentCommandHandler) Handle(ctx context.Context, command *IdempotentCommand) (*Response, error) {
+	atomic.AddInt32(&h.calls, 1)
+	time.Sleep(h.delay)
+	return &Response{}, nil
+}
+
+func TestIdempotencyBehavior_WhenCommandIsReplayed_ShouldReturnCachedResponse(t *testing.T) {
+	// arrange
+	defer commands_cleanup(t)
+	defer behaviors_cleanup(t)
+	handler := &IdempotentCommandHandler{}
+	RegisterCommandHandler[*IdempotentCommand, *Response](handler)
+	RegisterCommandPipelineBehavior(0, NewIdempotencyBehavior(NewMemoryIdempotencyStore(), time.Minute))
+
+	// act
+	first, _ := Send[*IdempotentCommand, *Response](context.TODO(), &IdempotentCommand{Key: "abc"})
+	second, err := Send[*IdempotentCommand, *Response](context.TODO(), &IdempotentCommand{Key: "abc"})
+
+	// assert
+	assert.Nil(t, err)
+	assert.Same(t, first, second)
+	assert.Equal(t, int32(1), handler.calls)
+}
+
+func TestIdempotencyBehavior_WhenKeyIsInContext_ShouldReturnCachedResponse(t *testing.T) {
+	// arrange
+	behavior := NewIdempotencyBehavior(NewMemoryIdempotencyStore(), time.Minute)
+	ctx := WithIdempotencyKey(context.TODO(), "abc")
+	calls := 0
+	next := func(ctx context.Context, request interface{}) (interface{}, error) {
+		calls++
+		return calls, nil
+	}
+
+	// act
+	behavior.Handle(ctx, &Command1{}, next)
+	res, err := behavior.Handle(ctx, &Command1{}, next)
+
+	// assert
+	assert.Nil(t, err)
+	assert.Equal(t, 1, res)
+	assert.Equal(t, 1, calls)
+}
+
+func TestIdempotencyBehavior_WhenNoKey_ShouldAlwaysCallNext(t *testing.T) {
+	// arrange
+	behavior := NewIdempotencyBehavior(NewMemoryIdempotencyStore(), time.Minute)
+	calls := 0
+	next := func(ctx context.Context, request interface{}) (interface{}, error) {
+		calls++
+		return calls, nil
+	}
+
+	// act
+	behavior.Handle(context.TODO(), &Command1{}, next)
+	res, _ := behavior.Handle(context.TODO(), &Command1{}, next)
+
+	// assert
+	assert.Equal(t, 2, res)
+	assert.Equal(t, 2, calls)
+}
+
+func TestIdempotencyBehavior_WhenHandlerFails_ShouldNotCacheResponse(t *testing.T) {
+	// arrange
+	behavior := NewIdempotencyBehavior(NewMemoryIdempotencyStore(), time.Minute)
+	ctx := WithIdempotencyKey(context.TODO(), "abc")
+	calls := 0
+	next := func(ctx context.Context, request interface{}) (interface{}, error) {
+		calls++
+		if calls == 1 {
+			return nil, errors.New("failed")
+		}
+		return calls, nil
+	}
+
+	// act
+	_, firstErr := behavior.Handle(ctx, &Command1{}, next)
+	res, err := behavior.Handle(ctx, &Command1{}, next)
+
+	// assert
+	assert.Error(t, firstErr)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, res)
+}
+
+type unwritableIdempotencyStore struct {
+	err error
+}
+
+func (s *unwritableIdempotencyStore) Get(ctx context.Context, key string) (interface{}, bool, error) {
+	return nil, false, nil
+}
+
+func (s *unwritableIdempotencyStore) Set(ctx context.Context, key string, response interface{}, ttl time.Duration) error {
+	return s.err
+}
+
+func TestIdempotencyBehavior_WhenStoreWriteFails_ShouldReturnResponseAndReportError(t *testing.T) {
+	// arrange
+	storeErr := errors.New("store unavailable")
+	var reported error
+	var reportedKey string
+	behavior := NewIdempotencyBehavior(&unwritableIdempotencyStore{err: storeErr}, time.Minute, OnIdempotencyError(func(ctx context.Context, key string, err error) {
+		reportedKey = key
+		reported = err
+	}))
+	ctx := WithIdempotencyKey(context.TODO(), "abc")
+	next := func(ctx context.Context, request interface{}) (interface{}, error) {
+		return "created", nil
+	}
+
+	// act
+	res, err := behavior.Handle(ctx, &Command1{}, next)
+
+	// assert
+	assert.Nil(t, err)
+	assert.Equal(t, "created", res)
+	assert.Equal(t, storeErr, reported)
+	assert.Equal(t, "*cqrs.Command1:abc", reportedKey)
+}
+
+func TestIdempotencyBehavior_WhenConcurrentDuplicates_ShouldCallHandlerOnce(t *testing.T) {
+	// arrange
+	behavior := NewIdempotencyBehavior(NewMemoryIdempotencyStore(), time.Minute)
+	handler := &IdempotentCommandHandler{delay: 20 * time.Millisecond}
+	command := &IdempotentCommand{Key: "abc"}
+	next := func(ctx context.Context, request interface{}) (interface{}, error) {
+		return handler.Handle(context.TODO(), command)
+	}
+	var wg sync.WaitGroup
+
+	// act
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			behavior.Handle(context.TODO(), command, next)
+		}()
+	}
+	wg.Wait()
+
+	// assert
+	assert.Equal(t, int32(1), handler.calls)
+}
+
+func TestMemoryIdempotencyStore_WhenEntryExpired_ShouldNotReturnEntry(t *testing.T) {
+	// arrange
+	store := NewMemoryIdempotencyStore()
+	now := time.Now()
+	store.now = func() time.Time {
+		return now
+	}
+	store.Set(context.TODO(), "key", "value", time.Second)
+	store.now = func() time.Time {
+		return now.Add(2 * time.Second)
+	}
+
+	// act
+	_, found, err := store.Get(context.TODO(), "key")
+
+	// assert
+	assert.Nil(t, err)
+	assert.False(t, found)
+}
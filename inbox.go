@@ -0,0 +1,280 @@
+package cqrs
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+type IdentifiableEvent interface {
+	GetEventID() string
+}
+
+type InboxStore interface {
+	Processed(ctx context.Context, messageID string, handler string) (bool, error)
+	MarkProcessed(ctx context.Context, messageID string, handler string, processedAt time.Time) error
+	Purge(ctx context.Context, before time.Time) error
+}
+
+type InboxErrorHook func(ctx context.Context, err error)
+
+type InboxOption func(behavior *InboxBehavior)
+
+func OnInboxError(hook InboxErrorHook) InboxOption {
+	return func(behavior *InboxBehavior) {
+		behavior.onError = hook
+	}
+}
+
+type InboxBehavior struct {
+	store     InboxStore
+	retention time.Duration
+	now       func() time.Time
+	onError   InboxErrorHook
+	mutex     sync.Mutex
+	lastPurge time.Time
+}
+
+func NewInboxBehavior(store InboxStore, retention time.Duration, options ...InboxOption) *InboxBehavior {
+	behavior := &InboxBehavior{
+		store:     store,
+		retention: retention,
+		now:       time.Now,
+	}
+
+	for _, option := range options {
+		option(behavior)
+	}
+
+	return behavior
+}
+
+func (b *InboxBehavior) Handle(ctx context.Context, event interface{}, handler interface{}, next EventNextFunc) error {
+	identifiable, ok := event.(IdentifiableEvent)
+
+	if !ok {
+		return next(ctx)
+	}
+
+	messageID := identifiable.GetEventID()
+
+	if messageID == "" {
+		return next(ctx)
+	}
+
+	name := handlerName(handler)
+
+	if info, ok := DispatchInfoFromContext(ctx); ok && info.Subscription != "" {
+		name = info.Subscription
+	}
+
+	processed, err := b.store.Processed(ctx, messageID, name)
+
+	if err != nil {
+		return err
+	}
+
+	if processed {
+		return nil
+	}
+
+	err = next(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	// the handler already succeeded, a failed write only means the event may
+	// be handled again if it is redelivered
+	now := b.now()
+
+	if err := b.store.MarkProcessed(ctx, messageID, name, now); err != nil {
+		b.reportError(ctx, err)
+	}
+
+	if err := b.purge(ctx, now); err != nil {
+		b.reportError(ctx, err)
+	}
+
+	return nil
+}
+
+func (b *InboxBehavior) purge(ctx context.Context, now time.Time) error {
+	if b.retention <= 0 {
+		return nil
+	}
+
+	b.mutex.Lock()
+
+	if now.Sub(b.lastPurge) < b.retention {
+		b.mutex.Unlock()
+		return nil
+	}
+
+	b.lastPurge = now
+	b.mutex.Unlock()
+
+	return b.store.Purge(ctx, now.Add(-b.retention))
+}
+
+func (b *InboxBehavior) reportError(ctx context.Context, err error) {
+	if b.onError != nil {
+		b.onError(ctx, err)
+	}
+}
+
+type inboxKey struct {
+	messageID string
+	handler   string
+}
+
+type MemoryInboxStore struct {
+	mutex   sync.RWMutex
+	records map[inboxKey]time.Time
+}
+
+func NewMemoryInboxStore() *MemoryInboxStore {
+	return &MemoryInboxStore{
+		records: make(map[inboxKey]time.Time),
+	}
+}
+
+func (s *MemoryInboxStore) Processed(ctx context.Context, messageID string, handler string) (bool, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	_, found := s.records[inboxKey{messageID: messageID, handler: handler}]
+
+	return found, nil
+}
+
+func (s *MemoryInboxStore) MarkProcessed(ctx context.Context, messageID string, handler string, processedAt time.Time) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.records[inboxKey{messageID: messageID, handler: handler}] = processedAt
+
+	return nil
+}
+
+func (s *MemoryInboxStore) Purge(ctx context.Context, before time.Time) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for key, processedAt := range s.records {
+		if processedAt.Before(before) {
+			delete(s.records, key)
+		}
+	}
+
+	return nil
+}
+
+type PlaceholderFormat int
+
+const (
+	QuestionPlaceholder PlaceholderFormat = iota
+	DollarPlaceholder
+)
+
+func (f PlaceholderFormat) placeholder(position int) string {
+	if f == DollarPlaceholder {
+		return fmt.Sprintf("$%d", position)
+	}
+
+	return "?"
+}
+
+type SQLInboxStore struct {
+	db          *sql.DB
+	table       string
+	placeholder PlaceholderFormat
+}
+
+func NewSQLInboxStore(db *sql.DB, table string, placeholder PlaceholderFormat) (*SQLInboxStore, error) {
+	if db == nil {
+		return nil, errors.New("a database handle must be provided")
+	}
+
+	if table == "" {
+		return nil, errors.New("an inbox table name must be provided")
+	}
+
+	store := &SQLInboxStore{
+		db:          db,
+		table:       table,
+		placeholder: placeholder,
+	}
+
+	return store, nil
+}
+
+func (s *SQLInboxStore) CreateTable(ctx context.Context) error {
+	query := fmt.Sprintf(
+		"CREATE TABLE IF NOT EXISTS %s (message_id VARCHAR(255) NOT NULL, handler VARCHAR(255) NOT NULL, processed_at TIMESTAMP NOT NULL, PRIMARY KEY (message_id, handler))",
+		s.table,
+	)
+
+	_, err := s.db.ExecContext(ctx, query)
+
+	return err
+}
+
+func (s *SQLInboxStore) Processed(ctx context.Context, messageID string, handler string) (bool, error) {
+	query := fmt.Sprintf(
+		"SELECT COUNT(1) FROM %s WHERE message_id = %s AND handler = %s",
+		s.table,
+		s.placeholder.placeholder(1),
+		s.placeholder.placeholder(2),
+	)
+
+	var count int
+
+	err := s.db.QueryRowContext(ctx, query, messageID, handler).Scan(&count)
+
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func (s *SQLInboxStore) MarkProcessed(ctx context.Context, messageID string, handler string, processedAt time.Time) error {
+	query := fmt.Sprintf(
+		"INSERT INTO %s (message_id, handler, processed_at) VALUES (%s, %s, %s)",
+		s.table,
+		s.placeholder.placeholder(1),
+		s.placeholder.placeholder(2),
+		s.placeholder.placeholder(3),
+	)
+
+	_, err := s.db.ExecContext(ctx, query, messageID, handler, processedAt.UTC())
+
+	if err == nil {
+		return nil
+	}
+
+	// a concurrent delivery may have recorded the same pair first
+	processed, checkErr := s.Processed(ctx, messageID, handler)
+
+	if checkErr == nil && processed {
+		return nil
+	}
+
+	return err
+}
+
+func (s *SQLInboxStore) Purge(ctx context.Context, before time.Time) error {
+	query := fmt.Sprintf(
+		"DELETE FROM %s WHERE processed_at < %s",
+		s.table,
+		s.placeholder.placeholder(1),
+	)
+
+	_, err := s.db.ExecContext(ctx, query, before.UTC())
+
+	return err
+}
@@ -0,0 +1,316 @@
+package cqrs
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	_ "modernc.org/sqlite"
+)
+
+type IdentifiedEvent struct {
+	ID string
+}
+
+func (e *IdentifiedEvent) GetEventID() string {
+	return e.ID
+}
+
+type CountingEventHandler struct {
+	calls int
+	err   error
+}
+
+func (h *CountingEventHandler) Handle(ctx context.Context, event *IdentifiedEvent) error {
+	h.calls++
+	return h.err
+}
+
+func newSQLiteInboxStore(t *testing.T) *SQLInboxStore {
+	db, err := sql.Open("sqlite", ":memory:")
+	assert.Nil(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() {
+		db.Close()
+	})
+
+	store, err := NewSQLInboxStore(db, "inbox", QuestionPlaceholder)
+	assert.Nil(t, err)
+	assert.Nil(t, store.CreateTable(context.TODO()))
+
+	return store
+}
+
+func TestInboxBehavior_WhenEventAlreadyProcessedByHandler_ShouldSkipHandler(t *testing.T) {
+	// arrange
+	defer events_cleanup(t)
+	defer behaviors_cleanup(t)
+	handler := &CountingEventHandler{}
+	event := &IdentifiedEvent{ID: "1"}
+	RegisterEventSubscriber[*IdentifiedEvent](handler)
+	RegisterEventBehavior(0, NewInboxBehavior(NewMemoryInboxStore(), time.Hour))
+
+	// act
+	PublishEvent(context.TODO(), event)
+	err := PublishEvent(context.TODO(), event)
+
+	// assert
+	assert.Nil(t, err)
+	assert.Equal(t, 1, handler.calls)
+}
+
+func TestInboxBehavior_WhenSubscribersShareHandlerType_ShouldCallEachOnce(t *testing.T) {
+	// arrange
+	defer events_cleanup(t)
+	defer behaviors_cleanup(t)
+	first, second := &CountingEventHandler{}, &CountingEventHandler{}
+	event := &IdentifiedEvent{ID: "1"}
+	RegisterEventSubscribers[*IdentifiedEvent](first, second)
+	RegisterEventBehavior(0, NewInboxBehavior(NewMemoryInboxStore(), time.Hour))
+
+	// act
+	PublishEvent(context.TODO(), event)
+	err := PublishEvent(context.TODO(), event)
+
+	// assert
+	assert.Nil(t, err)
+	assert.Equal(t, 1, first.calls)
+	assert.Equal(t, 1, second.calls)
+}
+
+func TestInboxBehavior_WhenCacheInvalidationsShareEvent_ShouldRunEachOnce(t *testing.T) {
+	// arrange
+	defer events_cleanup(t)
+	defer behaviors_cleanup(t)
+	invalidated := []string{}
+	RegisterEventBehavior(0, NewInboxBehavior(NewMemoryInboxStore(), time.Hour))
+	RegisterCacheInvalidation[*IdentifiedEvent, *CacheableQuery](&recordingQueryCache{deleted: &invalidated}, func(event *IdentifiedEvent) []string {
+		return []string{"first"}
+	})
+	RegisterCacheInvalidation[*IdentifiedEvent, *CacheableQuery](&recordingQueryCache{deleted: &invalidated}, func(event *IdentifiedEvent) []string {
+		return []string{"second"}
+	})
+
+	// act
+	err := PublishEvent(context.TODO(), &IdentifiedEvent{ID: "1"})
+
+	// assert
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"*cqrs.CacheableQuery:first", "*cqrs.CacheableQuery:second"}, invalidated)
+}
+
+func TestInboxBehavior_WhenEventProcessedByAnotherHandler_ShouldCallHandler(t *testing.T) {
+	// arrange
+	store := NewMemoryInboxStore()
+	behavior := NewInboxBehavior(store, time.Hour)
+	handler := &CountingEventHandler{}
+	event := &IdentifiedEvent{ID: "1"}
+	store.MarkProcessed(context.TODO(), "1", "*cqrs.FakeEventHandler1", time.Now())
+	next := func(ctx context.Context) error {
+		return handler.Handle(ctx, event)
+	}
+
+	// act
+	err := behavior.Handle(context.TODO(), event, handler, next)
+
+	// assert
+	assert.Nil(t, err)
+	assert.Equal(t, 1, handler.calls)
+}
+
+func TestInboxBehavior_WhenHandlerFails_ShouldNotMarkEventAsProcessed(t *testing.T) {
+	// arrange
+	store := NewMemoryInboxStore()
+	behavior := NewInboxBehavior(store, time.Hour)
+	handler := &CountingEventHandler{err: errors.New("failed")}
+	event := &IdentifiedEvent{ID: "1"}
+	next := func(ctx context.Context) error {
+		return handler.Handle(ctx, event)
+	}
+
+	// act
+	err := behavior.Handle(context.TODO(), event, handler, next)
+	processed, _ := store.Processed(context.TODO(), "1", handlerName(handler))
+
+	// assert
+	assert.Error(t, err)
+	assert.False(t, processed)
+}
+
+func TestInboxBehavior_WhenEventHasNoID_ShouldAlwaysCallHandler(t *testing.T) {
+	// arrange
+	behavior := NewInboxBehavior(NewMemoryInboxStore(), time.Hour)
+	calls := 0
+	next := func(ctx context.Context) error {
+		calls++
+		return nil
+	}
+
+	// act
+	behavior.Handle(context.TODO(), &FakeEvent{}, &FakeEventHandler1{}, next)
+	behavior.Handle(context.TODO(), &FakeEvent{}, &FakeEventHandler1{}, next)
+
+	// assert
+	assert.Equal(t, 2, calls)
+}
+
+func TestInboxBehavior_WhenRetentionElapsed_ShouldPurgeOldRecords(t *testing.T) {
+	// arrange
+	store := NewMemoryInboxStore()
+	behavior := NewInboxBehavior(store, time.Hour)
+	now := time.Now()
+	store.MarkProcessed(context.TODO(), "old", "handler", now.Add(-2*time.Hour))
+	behavior.now = func() time.Time {
+		return now
+	}
+	next := func(ctx context.Context) error {
+		return nil
+	}
+
+	// act
+	err := behavior.Handle(context.TODO(), &IdentifiedEvent{ID: "new"}, &FakeEventHandler1{}, next)
+	processed, _ := store.Processed(context.TODO(), "old", "handler")
+
+	// assert
+	assert.Nil(t, err)
+	assert.False(t, processed)
+}
+
+type unavailableInboxStore struct {
+	*MemoryInboxStore
+	err error
+}
+
+func (s *unavailableInboxStore) MarkProcessed(ctx context.Context, messageID string, handler string, processedAt time.Time) error {
+	return s.err
+}
+
+func (s *unavailableInboxStore) Purge(ctx context.Context, before time.Time) error {
+	return s.err
+}
+
+func TestInboxBehavior_WhenStoreWritesFail_ShouldReportErrorsAndSucceed(t *testing.T) {
+	// arrange
+	storeErr := errors.New("store unavailable")
+	reported := []error{}
+	behavior := NewInboxBehavior(&unavailableInboxStore{MemoryInboxStore: NewMemoryInboxStore(), err: storeErr}, time.Hour, OnInboxError(func(ctx context.Context, err error) {
+		reported = append(reported, err)
+	}))
+	handler := &CountingEventHandler{}
+	event := &IdentifiedEvent{ID: "1"}
+	next := func(ctx context.Context) error {
+		return handler.Handle(ctx, event)
+	}
+
+	// act
+	err := behavior.Handle(context.TODO(), event, handler, next)
+
+	// assert
+	assert.Nil(t, err)
+	assert.Equal(t, 1, handler.calls)
+	assert.Equal(t, []error{storeErr, storeErr}, reported)
+}
+
+func TestInboxBehavior_WhenNamedSubscribersAreReordered_ShouldKeepTheirRecords(t *testing.T) {
+	// arrange
+	defer events_cleanup(t)
+	defer behaviors_cleanup(t)
+	store := NewMemoryInboxStore()
+	first, second := &CountingEventHandler{}, &CountingEventHandler{}
+	event := &IdentifiedEvent{ID: "1"}
+	RegisterNamedEventSubscriber[*IdentifiedEvent]("first", first)
+	RegisterNamedEventSubscriber[*IdentifiedEvent]("second", second)
+	RegisterEventBehavior(0, NewInboxBehavior(store, time.Hour))
+	PublishEvent(context.TODO(), event)
+	eventHandlers = make(map[reflect.Type][]interface{})
+	RegisterNamedEventSubscriber[*IdentifiedEvent]("second", second)
+	RegisterNamedEventSubscriber[*IdentifiedEvent]("first", first)
+
+	// act
+	err := PublishEvent(context.TODO(), event)
+	processed, _ := store.Processed(context.TODO(), "1", "first")
+
+	// assert
+	assert.Nil(t, err)
+	assert.True(t, processed)
+	assert.Equal(t, 1, first.calls)
+	assert.Equal(t, 1, second.calls)
+}
+
+func TestNewSQLInboxStore_WhenTableIsEmpty_ShouldReturnError(t *testing.T) {
+	// arrange
+	db, _ := sql.Open("sqlite", ":memory:")
+	defer db.Close()
+
+	// act
+	_, err := NewSQLInboxStore(db, "", QuestionPlaceholder)
+
+	// assert
+	assert.Error(t, err)
+}
+
+func TestSQLInboxStore_WhenMarkedAsProcessed_ShouldReportProcessed(t *testing.T) {
+	// arrange
+	store := newSQLiteInboxStore(t)
+
+	// act
+	err := store.MarkProcessed(context.TODO(), "1", "handler", time.Now())
+	processed, _ := store.Processed(context.TODO(), "1", "handler")
+	otherHandler, _ := store.Processed(context.TODO(), "1", "other")
+
+	// assert
+	assert.Nil(t, err)
+	assert.True(t, processed)
+	assert.False(t, otherHandler)
+}
+
+func TestSQLInboxStore_WhenMarkedTwice_ShouldNotReturnError(t *testing.T) {
+	// arrange
+	store := newSQLiteInboxStore(t)
+	store.MarkProcessed(context.TODO(), "1", "handler", time.Now())
+
+	// act
+	err := store.MarkProcessed(context.TODO(), "1", "handler", time.Now())
+
+	// assert
+	assert.Nil(t, err)
+}
+
+func TestSQLInboxStore_WhenPurged_ShouldRemoveOlderRecords(t *testing.T) {
+	// arrange
+	store := newSQLiteInboxStore(t)
+	now := time.Now()
+	store.MarkProcessed(context.TODO(), "old", "handler", now.Add(-2*time.Hour))
+	store.MarkProcessed(context.TODO(), "new", "handler", now)
+
+	// act
+	err := store.Purge(context.TODO(), now.Add(-time.Hour))
+	old, _ := store.Processed(context.TODO(), "old", "handler")
+	recent, _ := store.Processed(context.TODO(), "new", "handler")
+
+	// assert
+	assert.Nil(t, err)
+	assert.False(t, old)
+	assert.True(t, recent)
+}
+
+type recordingQueryCache struct {
+	deleted *[]string
+}
+
+func (c *recordingQueryCache) Get(ctx context.Context, key string) (interface{}, bool, error) {
+	return nil, false, nil
+}
+
+func (c *recordingQueryCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	return nil
+}
+
+func (c *recordingQueryCache) Delete(ctx context.Context, keys ...string) error {
+	*c.deleted = append(*c.deleted, keys...)
+	return nil
+}
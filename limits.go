@@ -0,0 +1,356 @@
+package cqrs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
+
+type LimitMode int
+
+const (
+	LimitModeWait LimitMode = iota
+	LimitModeReject
+)
+
+var ErrRateLimited = errors.New("rate limit exceeded")
+var ErrConcurrencyLimited = errors.New("concurrency limit exceeded")
+
+type LimitKeyer interface {
+	LimitKey() string
+}
+
+type LimitKeyFunc func(ctx context.Context, request interface{}) string
+
+type LimitError struct {
+	MessageIDs
+	Key string
+	err error
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("%s for %s", e.err.Error(), e.Key)
+}
+
+func (e *LimitError) Unwrap() error {
+	return e.err
+}
+
+func limitKey(ctx context.Context, request interface{}, keyFunc LimitKeyFunc) string {
+	requestType := reflect.TypeOf(request).String()
+
+	var key string
+
+	if keyFunc != nil {
+		key = keyFunc(ctx, request)
+	} else if keyer, ok := request.(LimitKeyer); ok {
+		key = keyer.LimitKey()
+	}
+
+	if key == "" {
+		return requestType
+	}
+
+	return fmt.Sprintf("%s:%s", requestType, key)
+}
+
+type RateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+type RateLimitOption func(behavior *RateLimitBehavior)
+
+func RateLimitFor[TRequest any](limit RateLimit) RateLimitOption {
+	var request TRequest
+	requestType := reflect.TypeOf(request)
+
+	return func(behavior *RateLimitBehavior) {
+		behavior.limits[requestType] = limit
+	}
+}
+
+func RateLimitKey(keyFunc LimitKeyFunc) RateLimitOption {
+	return func(behavior *RateLimitBehavior) {
+		behavior.keyFunc = keyFunc
+	}
+}
+
+const bucketSweepInterval = time.Minute
+
+type tokenBucket struct {
+	limit   RateLimit
+	tokens  float64
+	updated time.Time
+}
+
+type RateLimitBehavior struct {
+	limit     RateLimit
+	limits    map[reflect.Type]RateLimit
+	mode      LimitMode
+	keyFunc   LimitKeyFunc
+	mutex     sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func NewRateLimitBehavior(limit RateLimit, mode LimitMode, options ...RateLimitOption) *RateLimitBehavior {
+	behavior := &RateLimitBehavior{
+		limit:   limit,
+		limits:  make(map[reflect.Type]RateLimit),
+		mode:    mode,
+		buckets: make(map[string]*tokenBucket),
+		now:     time.Now,
+	}
+
+	for _, option := range options {
+		option(behavior)
+	}
+
+	return behavior
+}
+
+func (b *RateLimitBehavior) Handle(ctx context.Context, request interface{}, next PipelineFunc) (interface{}, error) {
+	limit := b.limitFor(request)
+
+	if limit.Rate <= 0 {
+		return next(ctx, request)
+	}
+
+	key := limitKey(ctx, request, b.keyFunc)
+
+	wait, allowed := b.reserve(key, limit)
+
+	if !allowed {
+		return nil, &LimitError{MessageIDs: MessageIDsFromContext(ctx), Key: key, err: ErrRateLimited}
+	}
+
+	err := sleep(ctx, wait)
+
+	if err != nil {
+		b.cancel(key)
+		return nil, err
+	}
+
+	return next(ctx, request)
+}
+
+func (b *RateLimitBehavior) limitFor(request interface{}) RateLimit {
+	limit, found := b.limits[reflect.TypeOf(request)]
+
+	if found {
+		return limit
+	}
+
+	return b.limit
+}
+
+func (b *RateLimitBehavior) reserve(key string, limit RateLimit) (time.Duration, bool) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	now := b.now()
+	b.sweep(now)
+
+	burst := limit.burst()
+	bucket, found := b.buckets[key]
+
+	if !found {
+		bucket = &tokenBucket{
+			limit:   limit,
+			tokens:  burst,
+			updated: now,
+		}
+		b.buckets[key] = bucket
+	}
+
+	bucket.refill(now)
+
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		return 0, true
+	}
+
+	if b.mode == LimitModeReject {
+		return 0, false
+	}
+
+	bucket.tokens--
+	wait := time.Duration(-bucket.tokens / limit.Rate * float64(time.Second))
+
+	return wait, true
+}
+
+// a bucket that refilled to its burst is the same as a new one, so it can go
+func (b *RateLimitBehavior) sweep(now time.Time) {
+	if now.Sub(b.lastSweep) < bucketSweepInterval {
+		return
+	}
+
+	b.lastSweep = now
+
+	for key, bucket := range b.buckets {
+		bucket.refill(now)
+
+		if bucket.tokens >= bucket.limit.burst() {
+			delete(b.buckets, key)
+		}
+	}
+}
+
+func (limit RateLimit) burst() float64 {
+	if limit.Burst < 1 {
+		return 1
+	}
+
+	return float64(limit.Burst)
+}
+
+func (bucket *tokenBucket) refill(now time.Time) {
+	elapsed := now.Sub(bucket.updated).Seconds()
+
+	if elapsed <= 0 {
+		return
+	}
+
+	bucket.tokens += elapsed * bucket.limit.Rate
+
+	if bucket.tokens > bucket.limit.burst() {
+		bucket.tokens = bucket.limit.burst()
+	}
+
+	bucket.updated = now
+}
+
+func (b *RateLimitBehavior) cancel(key string) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	bucket, found := b.buckets[key]
+
+	if found {
+		bucket.tokens++
+	}
+}
+
+type ConcurrencyLimitOption func(behavior *ConcurrencyLimitBehavior)
+
+func ConcurrencyLimitFor[TRequest any](limit int) ConcurrencyLimitOption {
+	var request TRequest
+	requestType := reflect.TypeOf(request)
+
+	return func(behavior *ConcurrencyLimitBehavior) {
+		behavior.limits[requestType] = limit
+	}
+}
+
+func ConcurrencyLimitKey(keyFunc LimitKeyFunc) ConcurrencyLimitOption {
+	return func(behavior *ConcurrencyLimitBehavior) {
+		behavior.keyFunc = keyFunc
+	}
+}
+
+type semaphore struct {
+	slots chan struct{}
+	users int
+}
+
+type ConcurrencyLimitBehavior struct {
+	limit      int
+	limits     map[reflect.Type]int
+	mode       LimitMode
+	keyFunc    LimitKeyFunc
+	mutex      sync.Mutex
+	semaphores map[string]*semaphore
+}
+
+func NewConcurrencyLimitBehavior(limit int, mode LimitMode, options ...ConcurrencyLimitOption) *ConcurrencyLimitBehavior {
+	behavior := &ConcurrencyLimitBehavior{
+		limit:      limit,
+		limits:     make(map[reflect.Type]int),
+		mode:       mode,
+		semaphores: make(map[string]*semaphore),
+	}
+
+	for _, option := range options {
+		option(behavior)
+	}
+
+	return behavior
+}
+
+func (b *ConcurrencyLimitBehavior) Handle(ctx context.Context, request interface{}, next PipelineFunc) (interface{}, error) {
+	limit := b.limitFor(request)
+
+	if limit <= 0 {
+		return next(ctx, request)
+	}
+
+	key := limitKey(ctx, request, b.keyFunc)
+	slots := b.acquire(key, limit)
+
+	defer b.release(key)
+
+	if b.mode == LimitModeReject {
+		select {
+		case slots <- struct{}{}:
+		default:
+			return nil, &LimitError{MessageIDs: MessageIDsFromContext(ctx), Key: key, err: ErrConcurrencyLimited}
+		}
+	} else {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	defer func() {
+		<-slots
+	}()
+
+	return next(ctx, request)
+}
+
+func (b *ConcurrencyLimitBehavior) limitFor(request interface{}) int {
+	limit, found := b.limits[reflect.TypeOf(request)]
+
+	if found {
+		return limit
+	}
+
+	return b.limit
+}
+
+func (b *ConcurrencyLimitBehavior) acquire(key string, limit int) chan struct{} {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	s, found := b.semaphores[key]
+
+	if !found {
+		s = &semaphore{slots: make(chan struct{}, limit)}
+		b.semaphores[key] = s
+	}
+
+	s.users++
+
+	return s.slots
+}
+
+// the semaphore is dropped once no request holds or waits for it
+func (b *ConcurrencyLimitBehavior) release(key string) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	s := b.semaphores[key]
+	s.users--
+
+	if s.users == 0 {
+		delete(b.semaphores, key)
+	}
+}
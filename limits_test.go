@@ -0,0 +1,247 @@
+package cqrs
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type tenantKey struct{}
+
+type ExpensiveQuery struct {
+	Tenant string
+}
+
+func (q *ExpensiveQuery) LimitKey() string {
+	return q.Tenant
+}
+
+func TestRateLimitBehavior_WhenBurstExhaustedInRejectMode_ShouldReturnErrRateLimited(t *testing.T) {
+	// arrange
+	behavior := NewRateLimitBehavior(RateLimit{Rate: 1, Burst: 2}, LimitModeReject)
+	now := time.Now()
+	behavior.now = func() time.Time {
+		return now
+	}
+
+	// act
+	behavior.Handle(context.TODO(), &Command1{}, succeedingNext)
+	behavior.Handle(context.TODO(), &Command1{}, succeedingNext)
+	_, err := behavior.Handle(context.TODO(), &Command1{}, succeedingNext)
+
+	// assert
+	var limitErr *LimitError
+	assert.True(t, errors.Is(err, ErrRateLimited))
+	assert.True(t, errors.As(err, &limitErr))
+	assert.Equal(t, "*cqrs.Command1", limitErr.Key)
+}
+
+func TestRateLimitBehavior_WhenTokensRefilled_ShouldAllowRequest(t *testing.T) {
+	// arrange
+	behavior := NewRateLimitBehavior(RateLimit{Rate: 1, Burst: 1}, LimitModeReject)
+	now := time.Now()
+	behavior.now = func() time.Time {
+		return now
+	}
+	behavior.Handle(context.TODO(), &Command1{}, succeedingNext)
+	behavior.now = func() time.Time {
+		return now.Add(time.Second)
+	}
+
+	// act
+	_, err := behavior.Handle(context.TODO(), &Command1{}, succeedingNext)
+
+	// assert
+	assert.Nil(t, err)
+}
+
+func TestRateLimitBehavior_WhenBurstExhaustedInWaitMode_ShouldWaitForToken(t *testing.T) {
+	// arrange
+	behavior := NewRateLimitBehavior(RateLimit{Rate: 20, Burst: 1}, LimitModeWait)
+	behavior.Handle(context.TODO(), &Command1{}, succeedingNext)
+	started := time.Now()
+
+	// act
+	_, err := behavior.Handle(context.TODO(), &Command1{}, succeedingNext)
+
+	// assert
+	assert.Nil(t, err)
+	assert.GreaterOrEqual(t, time.Since(started), 40*time.Millisecond)
+}
+
+func TestRateLimitBehavior_WhenContextDoneWhileWaiting_ShouldReturnContextError(t *testing.T) {
+	// arrange
+	behavior := NewRateLimitBehavior(RateLimit{Rate: 0.1, Burst: 1}, LimitModeWait)
+	behavior.Handle(context.TODO(), &Command1{}, succeedingNext)
+	ctx, cancel := context.WithTimeout(context.TODO(), 10*time.Millisecond)
+	defer cancel()
+
+	// act
+	_, err := behavior.Handle(ctx, &Command1{}, succeedingNext)
+
+	// assert
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+}
+
+func TestRateLimitBehavior_WhenKeyedByRequest_ShouldLimitEachKeySeparately(t *testing.T) {
+	// arrange
+	behavior := NewRateLimitBehavior(RateLimit{Rate: 1, Burst: 1}, LimitModeReject)
+
+	// act
+	behavior.Handle(context.TODO(), &ExpensiveQuery{Tenant: "a"}, succeedingNext)
+	_, otherTenantErr := behavior.Handle(context.TODO(), &ExpensiveQuery{Tenant: "b"}, succeedingNext)
+	_, sameTenantErr := behavior.Handle(context.TODO(), &ExpensiveQuery{Tenant: "a"}, succeedingNext)
+
+	// assert
+	assert.Nil(t, otherTenantErr)
+	assert.True(t, errors.Is(sameTenantErr, ErrRateLimited))
+}
+
+func TestRateLimitBehavior_WhenKeyedByContext_ShouldUseKeyFunc(t *testing.T) {
+	// arrange
+	keyFunc := func(ctx context.Context, request interface{}) string {
+		tenant, _ := ctx.Value(tenantKey{}).(string)
+		return tenant
+	}
+	behavior := NewRateLimitBehavior(RateLimit{Rate: 1, Burst: 1}, LimitModeReject, RateLimitKey(keyFunc))
+	ctxA := context.WithValue(context.TODO(), tenantKey{}, "a")
+	ctxB := context.WithValue(context.TODO(), tenantKey{}, "b")
+
+	// act
+	behavior.Handle(ctxA, &Command1{}, succeedingNext)
+	_, err := behavior.Handle(ctxB, &Command1{}, succeedingNext)
+
+	// assert
+	assert.Nil(t, err)
+}
+
+func TestRateLimitBehavior_WhenNoLimitForType_ShouldCallNext(t *testing.T) {
+	// arrange
+	behavior := NewRateLimitBehavior(RateLimit{}, LimitModeReject, RateLimitFor[*Query1](RateLimit{Rate: 1, Burst: 1}))
+
+	// act
+	behavior.Handle(context.TODO(), &Command1{}, succeedingNext)
+	_, commandErr := behavior.Handle(context.TODO(), &Command1{}, succeedingNext)
+	behavior.Handle(context.TODO(), &Query1{}, succeedingNext)
+	_, queryErr := behavior.Handle(context.TODO(), &Query1{}, succeedingNext)
+
+	// assert
+	assert.Nil(t, commandErr)
+	assert.True(t, errors.Is(queryErr, ErrRateLimited))
+}
+
+func TestRateLimitBehavior_WhenKeyIsIdle_ShouldEvictItsBucket(t *testing.T) {
+	// arrange
+	behavior := NewRateLimitBehavior(RateLimit{Rate: 1, Burst: 2}, LimitModeReject)
+	now := time.Now()
+	behavior.now = func() time.Time {
+		return now
+	}
+	behavior.Handle(context.TODO(), &ExpensiveQuery{Tenant: "a"}, succeedingNext)
+	behavior.Handle(context.TODO(), &ExpensiveQuery{Tenant: "b"}, succeedingNext)
+	behavior.Handle(context.TODO(), &ExpensiveQuery{Tenant: "b"}, succeedingNext)
+	behavior.now = func() time.Time {
+		return now.Add(bucketSweepInterval)
+	}
+
+	// act
+	behavior.Handle(context.TODO(), &ExpensiveQuery{Tenant: "c"}, succeedingNext)
+
+	// assert
+	assert.Len(t, behavior.buckets, 1)
+	assert.Contains(t, behavior.buckets, "*cqrs.ExpensiveQuery:c")
+}
+
+func TestConcurrencyLimitBehavior_WhenLimitReachedInRejectMode_ShouldReturnErrConcurrencyLimited(t *testing.T) {
+	// arrange
+	behavior := NewConcurrencyLimitBehavior(1, LimitModeReject)
+	started := make(chan struct{})
+	release := make(chan struct{})
+	blockingNext := func(ctx context.Context, request interface{}) (interface{}, error) {
+		close(started)
+		<-release
+		return nil, nil
+	}
+	go behavior.Handle(context.TODO(), &ExpensiveQuery{}, blockingNext)
+	<-started
+
+	// act
+	_, err := behavior.Handle(context.TODO(), &ExpensiveQuery{}, succeedingNext)
+	close(release)
+
+	// assert
+	assert.True(t, errors.Is(err, ErrConcurrencyLimited))
+}
+
+func TestConcurrencyLimitBehavior_WhenLimitReachedInWaitMode_ShouldNeverExceedLimit(t *testing.T) {
+	// arrange
+	behavior := NewConcurrencyLimitBehavior(2, LimitModeWait)
+	var running int32
+	var max int32
+	next := func(ctx context.Context, request interface{}) (interface{}, error) {
+		current := atomic.AddInt32(&running, 1)
+		for {
+			observed := atomic.LoadInt32(&max)
+			if current <= observed || atomic.CompareAndSwapInt32(&max, observed, current) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		return nil, nil
+	}
+	var wg sync.WaitGroup
+
+	// act
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			behavior.Handle(context.TODO(), &ExpensiveQuery{}, next)
+		}()
+	}
+	wg.Wait()
+
+	// assert
+	assert.LessOrEqual(t, max, int32(2))
+}
+
+func TestConcurrencyLimitBehavior_WhenContextDoneWhileWaiting_ShouldReturnContextError(t *testing.T) {
+	// arrange
+	behavior := NewConcurrencyLimitBehavior(1, LimitModeWait, ConcurrencyLimitFor[*ExpensiveQuery](1))
+	started := make(chan struct{})
+	release := make(chan struct{})
+	blockingNext := func(ctx context.Context, request interface{}) (interface{}, error) {
+		close(started)
+		<-release
+		return nil, nil
+	}
+	go behavior.Handle(context.TODO(), &ExpensiveQuery{}, blockingNext)
+	<-started
+	ctx, cancel := context.WithTimeout(context.TODO(), 10*time.Millisecond)
+	defer cancel()
+
+	// act
+	_, err := behavior.Handle(ctx, &ExpensiveQuery{}, succeedingNext)
+	close(release)
+
+	// assert
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+}
+
+func TestConcurrencyLimitBehavior_WhenRequestsComplete_ShouldEvictSemaphores(t *testing.T) {
+	// arrange
+	behavior := NewConcurrencyLimitBehavior(1, LimitModeReject)
+
+	// act
+	behavior.Handle(context.TODO(), &ExpensiveQuery{Tenant: "a"}, succeedingNext)
+	behavior.Handle(context.TODO(), &ExpensiveQuery{Tenant: "b"}, failingNext)
+
+	// assert
+	assert.Empty(t, behavior.semaphores)
+}
@@ -0,0 +1,380 @@
+package cqrs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
+
+type IBatchQueryHandler[TQuery any, TResponse any] interface {
+	HandleBatch(ctx context.Context, queries []TQuery) (BatchResult[TResponse], error)
+}
+
+var batchQueryHandlers map[reflect.Type]interface{}
+
+func init() {
+	batchQueryHandlers = make(map[reflect.Type]interface{})
+}
+
+func RegisterBatchQueryHandler[TQuery any, TResponse any](handler IBatchQueryHandler[TQuery, TResponse]) error {
+	var query TQuery
+	queryType := reflect.TypeOf(query)
+
+	_, found := batchQueryHandlers[queryType]
+
+	if found {
+		msg := fmt.Sprintf("batch handler for query of type %s is already registered", queryType.String())
+		return errors.New(msg)
+	}
+
+	batchQueryHandlers[queryType] = handler
+
+	return nil
+}
+
+var ErrLoaderScopeMissing = errors.New("loader called without a scope, use WithLoaderScope")
+
+type loaderScopeContextKey struct{}
+
+type loaderScope struct {
+	mutex sync.Mutex
+	calls map[interface{}]map[string]interface{}
+}
+
+func WithLoaderScope(ctx context.Context) context.Context {
+	scope := &loaderScope{
+		calls: make(map[interface{}]map[string]interface{}),
+	}
+
+	return context.WithValue(ctx, loaderScopeContextKey{}, scope)
+}
+
+func (s *loaderScope) get(loader interface{}, key string) (interface{}, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	call, found := s.calls[loader][key]
+
+	return call, found
+}
+
+func (s *loaderScope) set(loader interface{}, key string, call interface{}) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.calls[loader] == nil {
+		s.calls[loader] = make(map[string]interface{})
+	}
+
+	s.calls[loader][key] = call
+}
+
+func (s *loaderScope) forget(loader interface{}, key string, call interface{}) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.calls[loader][key] == call {
+		delete(s.calls[loader], key)
+	}
+}
+
+type LoaderOption func(options *loaderOptions)
+
+type loaderOptions struct {
+	wait     time.Duration
+	maxBatch int
+}
+
+func LoaderWait(wait time.Duration) LoaderOption {
+	return func(options *loaderOptions) {
+		options.wait = wait
+	}
+}
+
+func LoaderMaxBatch(size int) LoaderOption {
+	return func(options *loaderOptions) {
+		options.maxBatch = size
+	}
+}
+
+type loaderCall[TResponse any] struct {
+	done     chan struct{}
+	response TResponse
+	err      error
+}
+
+type loaderBatch[TQuery any, TResponse any] struct {
+	ctx     context.Context
+	scope   *loaderScope
+	keys    []string
+	queries []TQuery
+	calls   map[string]*loaderCall[TResponse]
+	timer   *time.Timer
+}
+
+type Loader[TQuery any, TResponse any] struct {
+	key     func(query TQuery) string
+	options loaderOptions
+	mutex   sync.Mutex
+	pending map[*loaderScope]*loaderBatch[TQuery, TResponse]
+}
+
+func NewLoader[TQuery any, TResponse any](key func(query TQuery) string, options ...LoaderOption) (*Loader[TQuery, TResponse], error) {
+	if key == nil {
+		return nil, errors.New("a key function must be provided")
+	}
+
+	loader := &Loader[TQuery, TResponse]{
+		key: key,
+		options: loaderOptions{
+			wait:     time.Millisecond,
+			maxBatch: 100,
+		},
+		pending: make(map[*loaderScope]*loaderBatch[TQuery, TResponse]),
+	}
+
+	for _, option := range options {
+		option(&loader.options)
+	}
+
+	return loader, nil
+}
+
+func (l *Loader[TQuery, TResponse]) Load(ctx context.Context, query TQuery) (TResponse, error) {
+	call, err := l.enqueue(ctx, query)
+
+	if err != nil {
+		return *new(TResponse), err
+	}
+
+	select {
+	case <-call.done:
+		return call.response, call.err
+	case <-ctx.Done():
+		return *new(TResponse), ctx.Err()
+	}
+}
+
+func (l *Loader[TQuery, TResponse]) LoadMany(ctx context.Context, queries []TQuery) BatchResult[TResponse] {
+	calls := make([]*loaderCall[TResponse], len(queries))
+	result := make(BatchResult[TResponse], len(queries))
+
+	for i, query := range queries {
+		calls[i], result[i].Err = l.enqueue(ctx, query)
+	}
+
+	for i, call := range calls {
+		if call == nil {
+			continue
+		}
+
+		select {
+		case <-call.done:
+			result[i].Response, result[i].Err = call.response, call.err
+		case <-ctx.Done():
+			result[i].Err = ctx.Err()
+		}
+	}
+
+	return result
+}
+
+// batches run with the context of their first caller, so callers only share
+// a batch within one scope, where principal, tenant and transaction are the same
+func (l *Loader[TQuery, TResponse]) enqueue(ctx context.Context, query TQuery) (*loaderCall[TResponse], error) {
+	scope, ok := ctx.Value(loaderScopeContextKey{}).(*loaderScope)
+
+	if !ok {
+		return nil, ErrLoaderScopeMissing
+	}
+
+	key := l.key(query)
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if call, found := scope.get(l, key); found {
+		return call.(*loaderCall[TResponse]), nil
+	}
+
+	batch, found := l.pending[scope]
+
+	if !found {
+		batch = &loaderBatch[TQuery, TResponse]{
+			ctx:   context.WithoutCancel(ctx),
+			scope: scope,
+			calls: make(map[string]*loaderCall[TResponse]),
+		}
+		l.pending[scope] = batch
+		batch.timer = time.AfterFunc(l.options.wait, func() {
+			l.flush(batch)
+		})
+	}
+
+	if call, found := batch.calls[key]; found {
+		return call, nil
+	}
+
+	call := &loaderCall[TResponse]{
+		done: make(chan struct{}),
+	}
+
+	batch.keys = append(batch.keys, key)
+	batch.queries = append(batch.queries, query)
+	batch.calls[key] = call
+
+	scope.set(l, key, call)
+
+	if l.options.maxBatch > 0 && len(batch.queries) >= l.options.maxBatch {
+		batch.timer.Stop()
+		delete(l.pending, scope)
+		go l.run(batch)
+	}
+
+	return call, nil
+}
+
+func (l *Loader[TQuery, TResponse]) flush(batch *loaderBatch[TQuery, TResponse]) {
+	l.mutex.Lock()
+
+	if l.pending[batch.scope] != batch {
+		l.mutex.Unlock()
+		return
+	}
+
+	delete(l.pending, batch.scope)
+	l.mutex.Unlock()
+
+	l.run(batch)
+}
+
+func (l *Loader[TQuery, TResponse]) run(batch *loaderBatch[TQuery, TResponse]) {
+	result, err := l.fetch(batch.ctx, batch.queries)
+
+	for i, key := range batch.keys {
+		call := batch.calls[key]
+
+		if err != nil {
+			call.err = err
+		} else {
+			call.response, call.err = result[i].Response, result[i].Err
+		}
+
+		if call.err != nil {
+			batch.scope.forget(l, key, call)
+		}
+
+		close(call.done)
+	}
+}
+
+func (l *Loader[TQuery, TResponse]) fetch(ctx context.Context, queries []TQuery) (result BatchResult[TResponse], err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("batch of queries of type %T panicked: %v", *new(TQuery), p)
+		}
+	}()
+
+	var query TQuery
+	queryType := reflect.TypeOf(query)
+
+	if h, found := batchQueryHandlers[queryType]; found {
+		blocker := batchBlocker(queryBehaviors)
+
+		if blocker == nil {
+			return requestBatch[TQuery, TResponse](ctx, h, queries)
+		}
+
+		if _, found := queryHandlers[queryType]; !found {
+			return nil, batchNotSupported(query, blocker)
+		}
+	}
+
+	result = make(BatchResult[TResponse], len(queries))
+	var wg sync.WaitGroup
+
+	for i, query := range queries {
+		wg.Add(1)
+
+		go func(i int, query TQuery) {
+			defer wg.Done()
+			defer func() {
+				if p := recover(); p != nil {
+					result[i].Err = fmt.Errorf("query of type %T panicked: %v", query, p)
+				}
+			}()
+
+			result[i].Response, result[i].Err = Request[TQuery, TResponse](ctx, query)
+		}(i, query)
+	}
+
+	wg.Wait()
+
+	return result, nil
+}
+
+func requestBatch[TQuery any, TResponse any](ctx context.Context, h interface{}, queries []TQuery) (BatchResult[TResponse], error) {
+	handler, casted := h.(IBatchQueryHandler[TQuery, TResponse])
+
+	if !casted {
+		msg := fmt.Sprintf("batch handler of type %T is not assignable for query of type %T and response of type %T", h, *new(TQuery), *new(TResponse))
+		return nil, errors.New(msg)
+	}
+
+	result := make(BatchResult[TResponse], len(queries))
+	accepted := make([]TQuery, 0, len(queries))
+	positions := make([]int, 0, len(queries))
+
+	for i, query := range queries {
+		if err := checkBatchItem(ctx, queryBehaviors, query); err != nil {
+			result[i].Err = err
+			continue
+		}
+
+		accepted = append(accepted, query)
+		positions = append(positions, i)
+	}
+
+	if len(accepted) == 0 {
+		return result, nil
+	}
+
+	batchHandle := func(ctx context.Context, request interface{}) (interface{}, error) {
+		queries, casted := request.([]TQuery)
+
+		if !casted {
+			msg := fmt.Sprintf("batch of type %T can't be replaced by a request of type %T", *new([]TQuery), request)
+			return nil, errors.New(msg)
+		}
+
+		return handler.HandleBatch(ctx, queries)
+	}
+
+	info := DispatchInfo{
+		Kind:        QueryMessage,
+		MessageType: reflect.TypeOf(queries).String(),
+		HandlerType: handlerName(h),
+	}
+
+	res, err := dispatch(ctx, info, accepted, queryBehaviors, batchHandle)
+
+	if err != nil {
+		return nil, err
+	}
+
+	handled, casted := res.(BatchResult[TResponse])
+
+	if !casted || len(handled) != len(accepted) {
+		msg := fmt.Sprintf("batch handler of type %T must return one result per query", h)
+		return nil, errors.New(msg)
+	}
+
+	for i, item := range handled {
+		result[positions[i]] = item
+	}
+
+	return result, nil
+}
@@ -0,0 +1,288 @@
+package cqrs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type GetProductByID struct {
+	ID int
+}
+
+type ProductByIDBatchHandler struct {
+	mutex   sync.Mutex
+	batches [][]int
+}
+
+func (h *ProductByIDBatchHandler) HandleBatch(ctx context.Context, queries []*GetProductByID) (BatchResult[string], error) {
+	ids := []int{}
+	result := make(BatchResult[string], len(queries))
+
+	for i, query := range queries {
+		ids = append(ids, query.ID)
+
+		if query.ID < 0 {
+			result[i].Err = errors.New("not found")
+			continue
+		}
+
+		result[i].Response = fmt.Sprintf("product %d", query.ID)
+	}
+
+	h.mutex.Lock()
+	h.batches = append(h.batches, ids)
+	h.mutex.Unlock()
+
+	return result, nil
+}
+
+func (h *ProductByIDBatchHandler) count() int {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return len(h.batches)
+}
+
+type ProductByIDHandler struct {
+	mutex sync.Mutex
+	calls int
+}
+
+func (h *ProductByIDHandler) Handle(ctx context.Context, query *GetProductByID) (string, error) {
+	h.mutex.Lock()
+	h.calls++
+	h.mutex.Unlock()
+	return fmt.Sprintf("product %d", query.ID), nil
+}
+
+type ProductByIDPolicy struct {
+}
+
+func (p *ProductByIDPolicy) Authorize(ctx context.Context, principal Principal, query *GetProductByID) (bool, error) {
+	return query.ID != 2, nil
+}
+
+func loader_cleanup(t *testing.T) {
+	t.Cleanup(func() {
+		batchQueryHandlers = make(map[reflect.Type]interface{})
+	})
+}
+
+func newProductLoader(t *testing.T, options ...LoaderOption) *Loader[*GetProductByID, string] {
+	loader, err := NewLoader[*GetProductByID, string](func(query *GetProductByID) string {
+		return fmt.Sprint(query.ID)
+	}, options...)
+	assert.Nil(t, err)
+	return loader
+}
+
+func loadConcurrently(ctx context.Context, loader *Loader[*GetProductByID, string], ids ...int) []string {
+	responses := make([]string, len(ids))
+	var wg sync.WaitGroup
+
+	for i, id := range ids {
+		wg.Add(1)
+		go func(i int, id int) {
+			defer wg.Done()
+			responses[i], _ = loader.Load(ctx, &GetProductByID{ID: id})
+		}(i, id)
+	}
+
+	wg.Wait()
+
+	return responses
+}
+
+func TestLoader_WhenQueriesIssuedTogether_ShouldCoalesceIntoOneBatch(t *testing.T) {
+	// arrange
+	defer loader_cleanup(t)
+	handler := &ProductByIDBatchHandler{}
+	RegisterBatchQueryHandler[*GetProductByID, string](handler)
+	loader := newProductLoader(t, LoaderWait(20*time.Millisecond))
+
+	// act
+	responses := loadConcurrently(WithLoaderScope(context.TODO()), loader, 1, 2, 1, 3)
+
+	// assert
+	assert.Equal(t, []string{"product 1", "product 2", "product 1", "product 3"}, responses)
+	assert.Equal(t, 1, handler.count())
+	assert.ElementsMatch(t, []int{1, 2, 3}, handler.batches[0])
+}
+
+func TestLoader_WhenMaxBatchReached_ShouldSplitBatches(t *testing.T) {
+	// arrange
+	defer loader_cleanup(t)
+	handler := &ProductByIDBatchHandler{}
+	RegisterBatchQueryHandler[*GetProductByID, string](handler)
+	loader := newProductLoader(t, LoaderWait(20*time.Millisecond), LoaderMaxBatch(2))
+
+	// act
+	result := loader.LoadMany(WithLoaderScope(context.TODO()), []*GetProductByID{{ID: 1}, {ID: 2}, {ID: 3}})
+
+	// assert
+	assert.Nil(t, result.Err())
+	assert.Equal(t, []string{"product 1", "product 2", "product 3"}, result.Responses())
+	assert.Equal(t, 2, handler.count())
+}
+
+func TestLoader_WhenScopedContext_ShouldCacheResponses(t *testing.T) {
+	// arrange
+	defer loader_cleanup(t)
+	handler := &ProductByIDBatchHandler{}
+	RegisterBatchQueryHandler[*GetProductByID, string](handler)
+	loader := newProductLoader(t)
+	ctx := WithLoaderScope(context.TODO())
+
+	// act
+	first, _ := loader.Load(ctx, &GetProductByID{ID: 1})
+	second, _ := loader.Load(ctx, &GetProductByID{ID: 1})
+	loader.Load(WithLoaderScope(context.TODO()), &GetProductByID{ID: 1})
+
+	// assert
+	assert.Equal(t, first, second)
+	assert.Equal(t, 2, handler.count())
+}
+
+func TestLoader_WhenQueryFails_ShouldNotCacheError(t *testing.T) {
+	// arrange
+	defer loader_cleanup(t)
+	handler := &ProductByIDBatchHandler{}
+	RegisterBatchQueryHandler[*GetProductByID, string](handler)
+	loader := newProductLoader(t)
+	ctx := WithLoaderScope(context.TODO())
+
+	// act
+	_, first := loader.Load(ctx, &GetProductByID{ID: -1})
+	_, second := loader.Load(ctx, &GetProductByID{ID: -1})
+
+	// assert
+	assert.EqualError(t, first, "not found")
+	assert.EqualError(t, second, "not found")
+	assert.Equal(t, 2, handler.count())
+}
+
+func TestLoader_WhenNoBatchHandler_ShouldRequestEachUniqueQuery(t *testing.T) {
+	// arrange
+	defer querys_cleanup(t)
+	handler := &ProductByIDHandler{}
+	RegisterQueryHandler[*GetProductByID, string](handler)
+	loader := newProductLoader(t, LoaderWait(20*time.Millisecond))
+
+	// act
+	responses := loadConcurrently(WithLoaderScope(context.TODO()), loader, 1, 1, 2)
+
+	// assert
+	assert.Equal(t, []string{"product 1", "product 1", "product 2"}, responses)
+	assert.Equal(t, 2, handler.calls)
+}
+
+func TestLoader_WhenBatchHandlerRegistered_ShouldRunPipelineOncePerBatch(t *testing.T) {
+	// arrange
+	defer loader_cleanup(t)
+	defer behaviors_cleanup(t)
+	behavior := &CountingBehavior{}
+	RegisterBatchQueryHandler[*GetProductByID, string](&ProductByIDBatchHandler{})
+	RegisterQueryPipelineBehavior(0, behavior)
+	loader := newProductLoader(t)
+
+	// act
+	loader.LoadMany(WithLoaderScope(context.TODO()), []*GetProductByID{{ID: 1}, {ID: 2}})
+
+	// assert
+	assert.Len(t, behavior.requests, 1)
+	assert.Len(t, behavior.requests[0], 2)
+}
+
+func TestLoader_WhenContextHasNoScope_ShouldReturnErrLoaderScopeMissing(t *testing.T) {
+	// arrange
+	defer loader_cleanup(t)
+	handler := &ProductByIDBatchHandler{}
+	RegisterBatchQueryHandler[*GetProductByID, string](handler)
+	loader := newProductLoader(t)
+
+	// act
+	_, err := loader.Load(context.TODO(), &GetProductByID{ID: 1})
+	result := loader.LoadMany(context.TODO(), []*GetProductByID{{ID: 1}, {ID: 2}})
+
+	// assert
+	assert.ErrorIs(t, err, ErrLoaderScopeMissing)
+	assert.ErrorIs(t, result[0].Err, ErrLoaderScopeMissing)
+	assert.ErrorIs(t, result[1].Err, ErrLoaderScopeMissing)
+	assert.Equal(t, 0, handler.count())
+}
+
+func TestLoader_WhenCallersHaveDifferentScopes_ShouldNotShareBatch(t *testing.T) {
+	// arrange
+	defer loader_cleanup(t)
+	handler := &ProductByIDBatchHandler{}
+	RegisterBatchQueryHandler[*GetProductByID, string](handler)
+	loader := newProductLoader(t, LoaderWait(20*time.Millisecond))
+	var wg sync.WaitGroup
+	wg.Add(2)
+
+	// act
+	for _, id := range []int{1, 2} {
+		go func(id int) {
+			defer wg.Done()
+			loader.Load(WithLoaderScope(context.TODO()), &GetProductByID{ID: id})
+		}(id)
+	}
+
+	wg.Wait()
+
+	// assert
+	assert.Equal(t, 2, handler.count())
+}
+
+func TestLoader_WhenPolicyDeniesQuery_ShouldReturnErrorForThatQuery(t *testing.T) {
+	// arrange
+	defer loader_cleanup(t)
+	defer behaviors_cleanup(t)
+	defer authorization_cleanup(t)
+	handler := &ProductByIDBatchHandler{}
+	RegisterBatchQueryHandler[*GetProductByID, string](handler)
+	RegisterAuthorizationPolicy[*GetProductByID](&ProductByIDPolicy{})
+	RegisterQueryPipelineBehavior(0, NewAuthorizationBehavior())
+	loader := newProductLoader(t)
+	ctx := WithLoaderScope(WithPrincipal(context.TODO(), &FakePrincipal{ID: "1"}))
+
+	// act
+	result := loader.LoadMany(ctx, []*GetProductByID{{ID: 1}, {ID: 2}})
+
+	// assert
+	assert.Equal(t, "product 1", result[0].Response)
+	assert.Nil(t, result[0].Err)
+	assert.ErrorIs(t, result[1].Err, ErrForbidden)
+	assert.Equal(t, [][]int{{1}}, handler.batches)
+}
+
+func TestLoader_WhenBehaviorCantCheckItemsAndOnlyBatchHandler_ShouldReturnErrBatchNotSupported(t *testing.T) {
+	// arrange
+	defer loader_cleanup(t)
+	defer behaviors_cleanup(t)
+	handler := &ProductByIDBatchHandler{}
+	RegisterBatchQueryHandler[*GetProductByID, string](handler)
+	RegisterQueryPipelineBehavior(0, NewConcurrencyLimitBehavior(1, LimitModeReject))
+	loader := newProductLoader(t)
+
+	// act
+	_, err := loader.Load(WithLoaderScope(context.TODO()), &GetProductByID{ID: 1})
+
+	// assert
+	assert.ErrorIs(t, err, ErrBatchNotSupported)
+	assert.Equal(t, 0, handler.count())
+}
+
+func TestNewLoader_WhenKeyIsNil_ShouldReturnError(t *testing.T) {
+	// act
+	_, err := NewLoader[*GetProductByID, string](nil)
+
+	// assert
+	assert.Error(t, err)
+}
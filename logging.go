@@ -0,0 +1,259 @@
+package cqrs
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"reflect"
+	"sync/atomic"
+	"time"
+)
+
+type LoggingOption func(config *loggingConfig)
+
+type loggingConfig struct {
+	level      slog.Level
+	errorLevel slog.Level
+	levels     map[reflect.Type]slog.Level
+	sampleRate float64
+	logPayload bool
+	sample     func() float64
+	now        func() time.Time
+}
+
+func LogLevel(level slog.Level) LoggingOption {
+	return func(config *loggingConfig) {
+		config.level = level
+	}
+}
+
+func LogErrorLevel(level slog.Level) LoggingOption {
+	return func(config *loggingConfig) {
+		config.errorLevel = level
+	}
+}
+
+func LogLevelFor[TRequest any](level slog.Level) LoggingOption {
+	var request TRequest
+	requestType := reflect.TypeOf(request)
+
+	return func(config *loggingConfig) {
+		config.levels[requestType] = level
+	}
+}
+
+func LogSampleRate(rate float64) LoggingOption {
+	return func(config *loggingConfig) {
+		config.sampleRate = rate
+	}
+}
+
+func LogPayload(enabled bool) LoggingOption {
+	return func(config *loggingConfig) {
+		config.logPayload = enabled
+	}
+}
+
+func newLoggingConfig(options []LoggingOption) *loggingConfig {
+	config := &loggingConfig{
+		level:      slog.LevelInfo,
+		errorLevel: slog.LevelError,
+		levels:     make(map[reflect.Type]slog.Level),
+		sampleRate: 1,
+		logPayload: true,
+		sample:     rand.Float64,
+		now:        time.Now,
+	}
+
+	for _, option := range options {
+		option(config)
+	}
+
+	return config
+}
+
+type LoggingBehavior struct {
+	logger *slog.Logger
+	config *loggingConfig
+}
+
+func NewLoggingBehavior(logger *slog.Logger, options ...LoggingOption) *LoggingBehavior {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &LoggingBehavior{
+		logger: logger,
+		config: newLoggingConfig(options),
+	}
+}
+
+func (b *LoggingBehavior) Handle(ctx context.Context, request interface{}, next PipelineFunc) (res interface{}, err error) {
+	started := b.config.now()
+	defer b.config.log(ctx, b.logger, request, started, &err, nil)
+
+	return next(ctx, request)
+}
+
+func (b *LoggingBehavior) CheckBatchItem(ctx context.Context, request interface{}) error {
+	return nil
+}
+
+type EventLoggingBehavior struct {
+	logger *slog.Logger
+	config *loggingConfig
+}
+
+func NewEventLoggingBehavior(logger *slog.Logger, options ...LoggingOption) *EventLoggingBehavior {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &EventLoggingBehavior{
+		logger: logger,
+		config: newLoggingConfig(options),
+	}
+}
+
+func (b *EventLoggingBehavior) Handle(ctx context.Context, event interface{}, handler interface{}, next EventNextFunc) (err error) {
+	started := b.config.now()
+	defer b.config.log(ctx, b.logger, event, started, &err, nil)
+
+	return next(ctx)
+}
+
+type StreamLoggingBehavior struct {
+	logger *slog.Logger
+	config *loggingConfig
+}
+
+func NewStreamLoggingBehavior(logger *slog.Logger, options ...LoggingOption) *StreamLoggingBehavior {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &StreamLoggingBehavior{
+		logger: logger,
+		config: newLoggingConfig(options),
+	}
+}
+
+func (b *StreamLoggingBehavior) Handle(ctx context.Context, query interface{}, emit StreamEmitFunc, next StreamFunc) (err error) {
+	started := b.config.now()
+	var items int64
+	defer b.config.log(ctx, b.logger, query, started, &err, &items)
+
+	countingEmit := func(ctx context.Context, item interface{}) error {
+		err := emit(ctx, item)
+
+		if err == nil {
+			atomic.AddInt64(&items, 1)
+		}
+
+		return err
+	}
+
+	return next(ctx, query, countingEmit)
+}
+
+func (c *loggingConfig) log(ctx context.Context, logger *slog.Logger, request interface{}, started time.Time, err *error, items *int64) {
+	outcome := OutcomeSuccess
+	level := c.levelFor(request)
+	p := recover()
+
+	if p != nil {
+		outcome = OutcomePanic
+		level = c.errorLevel
+	} else if *err != nil {
+		outcome = OutcomeError
+		level = c.errorLevel
+	} else if !c.sampled() {
+		return
+	}
+
+	if logger.Enabled(ctx, level) {
+		logger.LogAttrs(ctx, level, logMessage(ctx, outcome), c.attributes(ctx, request, started, outcome, *err, p, items)...)
+	}
+
+	if p != nil {
+		panic(p)
+	}
+}
+
+func (c *loggingConfig) levelFor(request interface{}) slog.Level {
+	if level, found := lookupRequestType(c.levels, request); found {
+		return level
+	}
+
+	return c.level
+}
+
+func (c *loggingConfig) sampled() bool {
+	if c.sampleRate >= 1 {
+		return true
+	}
+
+	return c.sample() < c.sampleRate
+}
+
+func (c *loggingConfig) attributes(ctx context.Context, request interface{}, started time.Time, outcome string, err error, p interface{}, items *int64) []slog.Attr {
+	info, _ := DispatchInfoFromContext(ctx)
+
+	attrs := []slog.Attr{
+		slog.String("message_type", fmt.Sprintf("%T", request)),
+		slog.String("handler_type", info.HandlerType),
+		slog.Duration("duration", c.now().Sub(started)),
+		slog.String("outcome", outcome),
+	}
+
+	ids := MessageIDsFromContext(ctx)
+
+	if ids.MessageID != "" {
+		attrs = append(attrs, slog.String("message_id", ids.MessageID), slog.String("correlation_id", ids.CorrelationID))
+	}
+
+	if ids.CausationID != "" {
+		attrs = append(attrs, slog.String("causation_id", ids.CausationID))
+	}
+
+	if info.Kind == EventMessage {
+		attrs = append(attrs, slog.Bool("async", info.Async))
+	}
+
+	if items != nil {
+		attrs = append(attrs, slog.Int64("items", atomic.LoadInt64(items)))
+	}
+
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+
+	if p != nil {
+		attrs = append(attrs, slog.String("panic", fmt.Sprint(p)))
+	}
+
+	if c.logPayload {
+		attrs = append(attrs, slog.Any("payload", Redact(request)))
+	}
+
+	return attrs
+}
+
+func logMessage(ctx context.Context, outcome string) string {
+	info, _ := DispatchInfoFromContext(ctx)
+	kind := string(info.Kind)
+
+	if kind == "" {
+		kind = "request"
+	}
+
+	switch outcome {
+	case OutcomeError:
+		return kind + " failed"
+	case OutcomePanic:
+		return kind + " panicked"
+	}
+
+	return kind + " handled"
+}
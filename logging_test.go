@@ -0,0 +1,171 @@
+package cqrs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type LoginCommand struct {
+	Username string
+	Password string `cqrs:"sensitive"`
+	Card     *Card  `json:"card"`
+}
+
+type Card struct {
+	Number string `json:"number" cqrs:"sensitive"`
+	Holder string `json:"holder"`
+}
+
+type LoginCommandHandler struct {
+	err error
+}
+
+func (h *LoginCommandHandler) Handle(ctx context.Context, command *LoginCommand) (*Response, error) {
+	return &Response{}, h.err
+}
+
+func newJSONLogger(buffer *bytes.Buffer) *slog.Logger {
+	return slog.New(slog.NewJSONHandler(buffer, &slog.HandlerOptions{Level: slog.LevelDebug}))
+}
+
+func decodeLogLine(t *testing.T, buffer *bytes.Buffer) map[string]interface{} {
+	line := map[string]interface{}{}
+	assert.Nil(t, json.Unmarshal(buffer.Bytes(), &line))
+	return line
+}
+
+func TestLoggingBehavior_WhenCommandSucceeds_ShouldLogRedactedPayload(t *testing.T) {
+	// arrange
+	defer commands_cleanup(t)
+	defer behaviors_cleanup(t)
+	buffer := &bytes.Buffer{}
+	RegisterCommandHandler[*LoginCommand, *Response](&LoginCommandHandler{})
+	RegisterCommandPipelineBehavior(0, NewLoggingBehavior(newJSONLogger(buffer)))
+
+	// act
+	Send[*LoginCommand, *Response](context.TODO(), &LoginCommand{Username: "foo", Password: "bar", Card: &Card{Number: "4242", Holder: "foo"}})
+
+	// assert
+	line := decodeLogLine(t, buffer)
+	assert.Equal(t, "INFO", line["level"])
+	assert.Equal(t, "command handled", line["msg"])
+	assert.Equal(t, "*cqrs.LoginCommand", line["message_type"])
+	assert.Equal(t, "*cqrs.LoginCommandHandler", line["handler_type"])
+	assert.Equal(t, OutcomeSuccess, line["outcome"])
+	assert.Equal(t, map[string]interface{}{
+		"Username": "foo",
+		"Password": RedactedValue,
+		"card":     map[string]interface{}{"number": RedactedValue, "holder": "foo"},
+	}, line["payload"])
+}
+
+func TestLoggingBehavior_WhenCommandFails_ShouldLogErrorAtErrorLevel(t *testing.T) {
+	// arrange
+	buffer := &bytes.Buffer{}
+	behavior := NewLoggingBehavior(newJSONLogger(buffer), LogErrorLevel(slog.LevelWarn))
+	next := func(ctx context.Context, request interface{}) (interface{}, error) {
+		return nil, errors.New("failed")
+	}
+
+	// act
+	behavior.Handle(context.TODO(), &Command1{}, next)
+
+	// assert
+	line := decodeLogLine(t, buffer)
+	assert.Equal(t, "WARN", line["level"])
+	assert.Equal(t, OutcomeError, line["outcome"])
+	assert.Equal(t, "failed", line["error"])
+}
+
+func TestLoggingBehavior_WhenLevelOverriddenForType_ShouldUseTypeLevel(t *testing.T) {
+	// arrange
+	buffer := &bytes.Buffer{}
+	behavior := NewLoggingBehavior(newJSONLogger(buffer), LogLevelFor[*Command1](slog.LevelDebug), LogPayload(false))
+
+	// act
+	behavior.Handle(context.TODO(), &Command1{}, succeedingNext)
+
+	// assert
+	line := decodeLogLine(t, buffer)
+	assert.Equal(t, "DEBUG", line["level"])
+	assert.NotContains(t, line, "payload")
+}
+
+func TestLoggingBehavior_WhenNotSampled_ShouldOnlyLogErrors(t *testing.T) {
+	// arrange
+	buffer := &bytes.Buffer{}
+	behavior := NewLoggingBehavior(newJSONLogger(buffer), LogSampleRate(0.1))
+	behavior.config.sample = func() float64 {
+		return 0.5
+	}
+
+	// act
+	behavior.Handle(context.TODO(), &Command1{}, succeedingNext)
+	skipped := buffer.Len()
+	behavior.Handle(context.TODO(), &Command1{}, failingNext)
+
+	// assert
+	assert.Equal(t, 0, skipped)
+	assert.Equal(t, OutcomeError, decodeLogLine(t, buffer)["outcome"])
+}
+
+func TestLoggingBehavior_WhenHandlerPanics_ShouldLogAndPanic(t *testing.T) {
+	// arrange
+	buffer := &bytes.Buffer{}
+	behavior := NewLoggingBehavior(newJSONLogger(buffer))
+	next := func(ctx context.Context, request interface{}) (interface{}, error) {
+		panic("boom")
+	}
+
+	// act
+	handle := func() {
+		behavior.Handle(context.TODO(), &Command1{}, next)
+	}
+
+	// assert
+	assert.PanicsWithValue(t, "boom", handle)
+	line := decodeLogLine(t, buffer)
+	assert.Equal(t, OutcomePanic, line["outcome"])
+	assert.Equal(t, "boom", line["panic"])
+}
+
+func TestEventLoggingBehavior_WhenEventPublished_ShouldLogEvent(t *testing.T) {
+	// arrange
+	defer events_cleanup(t)
+	defer behaviors_cleanup(t)
+	buffer := &bytes.Buffer{}
+	RegisterEventSubscriber[*FakeEvent](&FakeEventHandler1{})
+	RegisterEventBehavior(0, NewEventLoggingBehavior(newJSONLogger(buffer)))
+
+	// act
+	PublishEvent(context.TODO(), &FakeEvent{Message: "test"})
+
+	// assert
+	line := decodeLogLine(t, buffer)
+	assert.Equal(t, "event handled", line["msg"])
+	assert.Equal(t, "*cqrs.FakeEventHandler1", line["handler_type"])
+	assert.Equal(t, false, line["async"])
+}
+
+func TestRedact_WhenValueHasSensitiveFields_ShouldRedactNestedValues(t *testing.T) {
+	// arrange
+	value := map[string]interface{}{
+		"logins": []*LoginCommand{{Username: "foo", Password: "bar"}},
+	}
+
+	// act
+	redacted := Redact(value)
+
+	// assert
+	assert.Equal(t, map[string]interface{}{
+		"logins": []interface{}{
+			map[string]interface{}{"Username": "foo", "Password": RedactedValue, "card": nil},
+		},
+	}, redacted)
+}
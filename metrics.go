@@ -0,0 +1,103 @@
+package cqrs
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	OutcomeSuccess = "success"
+	OutcomeError   = "error"
+	OutcomePanic   = "panic"
+)
+
+type DispatchMetric struct {
+	Kind        MessageKind
+	MessageType string
+	HandlerType string
+	Async       bool
+	Outcome     string
+	Duration    time.Duration
+}
+
+type Metrics interface {
+	RecordDispatch(ctx context.Context, metric DispatchMetric)
+	RecordAsyncQueueDepth(depth int64)
+	RecordAsyncWorkers(busy int64, total int64)
+}
+
+type noopMetrics struct {
+}
+
+func (m noopMetrics) RecordDispatch(ctx context.Context, metric DispatchMetric) {
+}
+
+func (m noopMetrics) RecordAsyncQueueDepth(depth int64) {
+}
+
+func (m noopMetrics) RecordAsyncWorkers(busy int64, total int64) {
+}
+
+// the holder keeps the stored type the same for atomic.Value
+type metricsHolder struct {
+	Metrics
+}
+
+var metrics atomic.Value
+
+var asyncQueueDepth int64
+var asyncWorkers int64
+var asyncBusyWorkers int64
+
+func init() {
+	metrics.Store(metricsHolder{noopMetrics{}})
+}
+
+func SetMetrics(m Metrics) {
+	if m == nil {
+		m = noopMetrics{}
+	}
+
+	metrics.Store(metricsHolder{m})
+}
+
+func currentMetrics() Metrics {
+	return metrics.Load().(metricsHolder).Metrics
+}
+
+func recordDispatch(ctx context.Context, info DispatchInfo, started time.Time, err *error) {
+	metric := DispatchMetric{
+		Kind:        info.Kind,
+		MessageType: info.MessageType,
+		HandlerType: info.HandlerType,
+		Async:       info.Async,
+		Outcome:     OutcomeSuccess,
+	}
+
+	if p := recover(); p != nil {
+		metric.Outcome = OutcomePanic
+		metric.Duration = time.Since(started)
+		currentMetrics().RecordDispatch(ctx, metric)
+		panic(p)
+	}
+
+	if *err != nil {
+		metric.Outcome = OutcomeError
+	}
+
+	metric.Duration = time.Since(started)
+	currentMetrics().RecordDispatch(ctx, metric)
+}
+
+func addAsyncQueueDepth(delta int64) {
+	currentMetrics().RecordAsyncQueueDepth(atomic.AddInt64(&asyncQueueDepth, delta))
+}
+
+func addAsyncWorkers(delta int64) {
+	currentMetrics().RecordAsyncWorkers(atomic.LoadInt64(&asyncBusyWorkers), atomic.AddInt64(&asyncWorkers, delta))
+}
+
+func addAsyncBusyWorkers(delta int64) {
+	currentMetrics().RecordAsyncWorkers(atomic.AddInt64(&asyncBusyWorkers, delta), atomic.LoadInt64(&asyncWorkers))
+}
@@ -0,0 +1,183 @@
+package cqrs
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type MeteredCommand struct {
+	Fail  bool
+	Panic bool
+}
+
+type MeteredCommandHandler struct {
+}
+
+func (h *MeteredCommandHandler) Handle(ctx context.Context, command *MeteredCommand) (*Response, error) {
+	if command.Panic {
+		panic("boom")
+	}
+
+	if command.Fail {
+		return nil, errors.New("failed")
+	}
+
+	return &Response{}, nil
+}
+
+type FakeMetrics struct {
+	mutex      sync.Mutex
+	dispatches []DispatchMetric
+}
+
+func (m *FakeMetrics) RecordDispatch(ctx context.Context, metric DispatchMetric) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.dispatches = append(m.dispatches, metric)
+}
+
+func (m *FakeMetrics) RecordAsyncQueueDepth(depth int64) {
+}
+
+func (m *FakeMetrics) RecordAsyncWorkers(busy int64, total int64) {
+}
+
+func (m *FakeMetrics) recorded(kind MessageKind) []DispatchMetric {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	recorded := []DispatchMetric{}
+
+	for _, metric := range m.dispatches {
+		if metric.Kind == kind {
+			recorded = append(recorded, metric)
+		}
+	}
+
+	return recorded
+}
+
+func metrics_cleanup(t *testing.T, m Metrics) {
+	SetMetrics(m)
+	t.Cleanup(func() {
+		SetMetrics(nil)
+	})
+}
+
+func TestSend_WhenMetricsSet_ShouldRecordDispatch(t *testing.T) {
+	// arrange
+	defer commands_cleanup(t)
+	m := &FakeMetrics{}
+	metrics_cleanup(t, m)
+	RegisterCommandHandler[*MeteredCommand, *Response](&MeteredCommandHandler{})
+
+	// act
+	Send[*MeteredCommand, *Response](context.TODO(), &MeteredCommand{})
+	Send[*MeteredCommand, *Response](context.TODO(), &MeteredCommand{Fail: true})
+
+	// assert
+	recorded := m.recorded(CommandMessage)
+	assert.Len(t, recorded, 2)
+	assert.Equal(t, "*cqrs.MeteredCommand", recorded[0].MessageType)
+	assert.Equal(t, "*cqrs.MeteredCommandHandler", recorded[0].HandlerType)
+	assert.Equal(t, OutcomeSuccess, recorded[0].Outcome)
+	assert.Equal(t, OutcomeError, recorded[1].Outcome)
+}
+
+func TestSend_WhenHandlerPanics_ShouldRecordPanicOutcome(t *testing.T) {
+	// arrange
+	defer commands_cleanup(t)
+	m := &FakeMetrics{}
+	metrics_cleanup(t, m)
+	RegisterCommandHandler[*MeteredCommand, *Response](&MeteredCommandHandler{})
+
+	// act
+	send := func() {
+		Send[*MeteredCommand, *Response](context.TODO(), &MeteredCommand{Panic: true})
+	}
+
+	// assert
+	assert.PanicsWithValue(t, "boom", send)
+	recorded := m.recorded(CommandMessage)
+	assert.Len(t, recorded, 1)
+	assert.Equal(t, OutcomePanic, recorded[0].Outcome)
+}
+
+func TestRequest_WhenMetricsSet_ShouldRecordDispatch(t *testing.T) {
+	// arrange
+	defer querys_cleanup(t)
+	m := &FakeMetrics{}
+	metrics_cleanup(t, m)
+	RegisterQueryHandler[*Query1, *Response](&QueryHandler1{})
+
+	// act
+	Request[*Query1, *Response](context.TODO(), &Query1{})
+
+	// assert
+	recorded := m.recorded(QueryMessage)
+	assert.Len(t, recorded, 1)
+	assert.Equal(t, "*cqrs.QueryHandler1", recorded[0].HandlerType)
+}
+
+func TestPublishEvent_WhenMetricsSet_ShouldRecordDispatchPerHandler(t *testing.T) {
+	// arrange
+	defer events_cleanup(t)
+	m := &FakeMetrics{}
+	metrics_cleanup(t, m)
+	RegisterEventSubscriber[*FakeEvent](&FakeEventHandler1{})
+	RegisterEventSubscriber[*FakeEvent](&FakeEventHandler2{})
+
+	// act
+	PublishEvent(context.TODO(), &FakeEvent{})
+
+	// assert
+	recorded := m.recorded(EventMessage)
+	assert.Len(t, recorded, 2)
+	assert.False(t, recorded[0].Async)
+}
+
+func TestSetMetrics_WhenNil_ShouldResetToNoop(t *testing.T) {
+	// arrange
+	SetMetrics(&FakeMetrics{})
+
+	// act
+	SetMetrics(nil)
+
+	// assert
+	assert.Equal(t, noopMetrics{}, currentMetrics())
+}
+
+func TestSetMetrics_WhenDispatching_ShouldBeSafeForConcurrentUse(t *testing.T) {
+	// arrange
+	defer commands_cleanup(t)
+	defer SetMetrics(nil)
+	RegisterCommandHandler[*MeteredCommand, *Response](&MeteredCommandHandler{})
+	var wg sync.WaitGroup
+	wg.Add(2)
+
+	// act
+	go func() {
+		defer wg.Done()
+
+		for i := 0; i < 100; i++ {
+			SetMetrics(&FakeMetrics{})
+		}
+	}()
+
+	go func() {
+		defer wg.Done()
+
+		for i := 0; i < 100; i++ {
+			Send[*MeteredCommand, *Response](context.TODO(), &MeteredCommand{})
+		}
+	}()
+
+	wg.Wait()
+
+	// assert
+	assert.NotNil(t, currentMetrics())
+}
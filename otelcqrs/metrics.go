@@ -0,0 +1,110 @@
+package otelcqrs
+
+import (
+	"context"
+	"sync/atomic"
+
+	cqrs "github.com/mitz-it/golang-cqrs"
+	"go.opentelemetry.io/otel/metric"
+)
+
+type Metrics struct {
+	dispatches  metric.Int64Counter
+	duration    metric.Float64Histogram
+	queueDepth  int64
+	busyWorkers int64
+	workers     int64
+}
+
+func NewMetrics(options ...Option) (*Metrics, error) {
+	meter := newConfig(options).meterProvider.Meter(instrumentationName)
+	m := &Metrics{}
+
+	var err error
+
+	m.dispatches, err = meter.Int64Counter("cqrs.dispatch.count",
+		metric.WithDescription("Number of dispatched commands, queries and events."),
+		metric.WithUnit("{dispatch}"))
+
+	if err != nil {
+		return nil, err
+	}
+
+	m.duration, err = meter.Float64Histogram("cqrs.dispatch.duration",
+		metric.WithDescription("Duration of dispatched commands, queries and events."),
+		metric.WithUnit("s"))
+
+	if err != nil {
+		return nil, err
+	}
+
+	queueDepth, err := meter.Int64ObservableGauge("cqrs.async.queue.depth",
+		metric.WithDescription("Number of events waiting for an async listener."),
+		metric.WithUnit("{event}"))
+
+	if err != nil {
+		return nil, err
+	}
+
+	busyWorkers, err := meter.Int64ObservableGauge("cqrs.async.workers.busy",
+		metric.WithDescription("Number of async listeners handling an event."),
+		metric.WithUnit("{worker}"))
+
+	if err != nil {
+		return nil, err
+	}
+
+	utilization, err := meter.Float64ObservableGauge("cqrs.async.workers.utilization",
+		metric.WithDescription("Ratio of busy async listeners to running async listeners."),
+		metric.WithUnit("1"))
+
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = meter.RegisterCallback(func(ctx context.Context, observer metric.Observer) error {
+		busy, total := atomic.LoadInt64(&m.busyWorkers), atomic.LoadInt64(&m.workers)
+
+		observer.ObserveInt64(queueDepth, atomic.LoadInt64(&m.queueDepth))
+		observer.ObserveInt64(busyWorkers, busy)
+		observer.ObserveFloat64(utilization, ratio(busy, total))
+
+		return nil
+	}, queueDepth, busyWorkers, utilization)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+func (m *Metrics) RecordDispatch(ctx context.Context, dispatch cqrs.DispatchMetric) {
+	attrs := metric.WithAttributes(
+		MessageKindKey.String(string(dispatch.Kind)),
+		MessageTypeKey.String(dispatch.MessageType),
+		HandlerTypeKey.String(dispatch.HandlerType),
+		AsyncKey.Bool(dispatch.Async),
+		OutcomeKey.String(dispatch.Outcome),
+	)
+
+	m.dispatches.Add(ctx, 1, attrs)
+	m.duration.Record(ctx, dispatch.Duration.Seconds(), attrs)
+}
+
+func (m *Metrics) RecordAsyncQueueDepth(depth int64) {
+	atomic.StoreInt64(&m.queueDepth, depth)
+}
+
+func (m *Metrics) RecordAsyncWorkers(busy int64, total int64) {
+	atomic.StoreInt64(&m.busyWorkers, busy)
+	atomic.StoreInt64(&m.workers, total)
+}
+
+func ratio(busy int64, total int64) float64 {
+	if total <= 0 {
+		return 0
+	}
+
+	return float64(busy) / float64(total)
+}
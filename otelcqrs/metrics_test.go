@@ -0,0 +1,84 @@
+package otelcqrs
+
+import (
+	"context"
+	"testing"
+
+	cqrs "github.com/mitz-it/golang-cqrs"
+	"github.com/stretchr/testify/assert"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func newTestMetrics(t *testing.T) (*Metrics, *sdkmetric.ManualReader) {
+	reader := sdkmetric.NewManualReader()
+	m, err := NewMetrics(WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))))
+	assert.Nil(t, err)
+	return m, reader
+}
+
+func collect(t *testing.T, reader *sdkmetric.ManualReader) map[string]metricdata.Metrics {
+	var rm metricdata.ResourceMetrics
+	assert.Nil(t, reader.Collect(context.TODO(), &rm))
+
+	collected := make(map[string]metricdata.Metrics)
+
+	for _, scope := range rm.ScopeMetrics {
+		for _, m := range scope.Metrics {
+			collected[m.Name] = m
+		}
+	}
+
+	return collected
+}
+
+func TestMetrics_WhenCommandDispatched_ShouldRecordCountAndDuration(t *testing.T) {
+	// arrange
+	m, reader := newTestMetrics(t)
+	cqrs.SetMetrics(m)
+	defer cqrs.SetMetrics(nil)
+
+	// act
+	cqrs.Send[*CreateProduct, *Product](context.TODO(), &CreateProduct{})
+	cqrs.Send[*CreateProduct, *Product](context.TODO(), &CreateProduct{Fail: true})
+
+	// assert
+	collected := collect(t, reader)
+	count := collected["cqrs.dispatch.count"].Data.(metricdata.Sum[int64])
+	duration := collected["cqrs.dispatch.duration"].Data.(metricdata.Histogram[float64])
+	assert.Len(t, count.DataPoints, 2)
+	assert.Len(t, duration.DataPoints, 2)
+
+	for _, point := range count.DataPoints {
+		kind, _ := point.Attributes.Value(MessageKindKey)
+		handler, _ := point.Attributes.Value(HandlerTypeKey)
+		assert.Equal(t, "command", kind.AsString())
+		assert.Equal(t, "*otelcqrs.CreateProductHandler", handler.AsString())
+		assert.Equal(t, int64(1), point.Value)
+	}
+
+	outcomes := []string{}
+	for _, point := range count.DataPoints {
+		outcome, _ := point.Attributes.Value(OutcomeKey)
+		outcomes = append(outcomes, outcome.AsString())
+	}
+	assert.ElementsMatch(t, []string{cqrs.OutcomeSuccess, cqrs.OutcomeError}, outcomes)
+}
+
+func TestMetrics_WhenAsyncStatsRecorded_ShouldObserveGauges(t *testing.T) {
+	// arrange
+	m, reader := newTestMetrics(t)
+
+	// act
+	m.RecordAsyncQueueDepth(3)
+	m.RecordAsyncWorkers(1, 4)
+
+	// assert
+	collected := collect(t, reader)
+	depth := collected["cqrs.async.queue.depth"].Data.(metricdata.Gauge[int64])
+	busy := collected["cqrs.async.workers.busy"].Data.(metricdata.Gauge[int64])
+	utilization := collected["cqrs.async.workers.utilization"].Data.(metricdata.Gauge[float64])
+	assert.Equal(t, int64(3), depth.DataPoints[0].Value)
+	assert.Equal(t, int64(1), busy.DataPoints[0].Value)
+	assert.Equal(t, 0.25, utilization.DataPoints[0].Value)
+}
@@ -0,0 +1,163 @@
+package otelcqrs
+
+import (
+	"context"
+	"fmt"
+
+	cqrs "github.com/mitz-it/golang-cqrs"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/mitz-it/golang-cqrs/otelcqrs"
+
+const (
+	MessageKindKey   = attribute.Key("cqrs.message.kind")
+	MessageTypeKey   = attribute.Key("cqrs.message.type")
+	HandlerTypeKey   = attribute.Key("cqrs.handler.type")
+	AsyncKey         = attribute.Key("cqrs.async")
+	OutcomeKey       = attribute.Key("cqrs.outcome")
+	MessageIDKey     = attribute.Key("cqrs.message.id")
+	CorrelationIDKey = attribute.Key("cqrs.correlation.id")
+	CausationIDKey   = attribute.Key("cqrs.causation.id")
+)
+
+type config struct {
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+}
+
+type Option func(c *config)
+
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(c *config) {
+		c.tracerProvider = provider
+	}
+}
+
+func WithMeterProvider(provider metric.MeterProvider) Option {
+	return func(c *config) {
+		c.meterProvider = provider
+	}
+}
+
+func newConfig(options []Option) *config {
+	c := &config{
+		tracerProvider: otel.GetTracerProvider(),
+		meterProvider:  otel.GetMeterProvider(),
+	}
+
+	for _, option := range options {
+		option(c)
+	}
+
+	return c
+}
+
+func newTracer(options []Option) trace.Tracer {
+	return newConfig(options).tracerProvider.Tracer(instrumentationName)
+}
+
+type TracingBehavior struct {
+	tracer trace.Tracer
+}
+
+func NewTracingBehavior(options ...Option) *TracingBehavior {
+	return &TracingBehavior{
+		tracer: newTracer(options),
+	}
+}
+
+func (b *TracingBehavior) Handle(ctx context.Context, request interface{}, next cqrs.PipelineFunc) (res interface{}, err error) {
+	info, _ := cqrs.DispatchInfoFromContext(ctx)
+
+	ctx, span := b.tracer.Start(ctx, spanName(info, request), trace.WithAttributes(attributes(ctx, info, request)...))
+	defer endSpan(span, &err)
+
+	return next(ctx, request)
+}
+
+func (b *TracingBehavior) CheckBatchItem(ctx context.Context, request interface{}) error {
+	return nil
+}
+
+type EventTracingBehavior struct {
+	tracer trace.Tracer
+}
+
+func NewEventTracingBehavior(options ...Option) *EventTracingBehavior {
+	return &EventTracingBehavior{
+		tracer: newTracer(options),
+	}
+}
+
+func (b *EventTracingBehavior) Handle(ctx context.Context, event interface{}, handler interface{}, next cqrs.EventNextFunc) (err error) {
+	info, _ := cqrs.DispatchInfoFromContext(ctx)
+
+	startOptions := []trace.SpanStartOption{
+		trace.WithAttributes(attributes(ctx, info, event)...),
+	}
+
+	publisher := trace.SpanContextFromContext(ctx)
+
+	if info.Async && publisher.IsValid() {
+		startOptions = append(startOptions, trace.WithNewRoot(), trace.WithLinks(trace.Link{SpanContext: publisher}))
+	}
+
+	ctx, span := b.tracer.Start(ctx, spanName(info, event), startOptions...)
+	defer endSpan(span, &err)
+
+	return next(ctx)
+}
+
+func spanName(info cqrs.DispatchInfo, message interface{}) string {
+	if info.Kind == "" {
+		return fmt.Sprintf("%T", message)
+	}
+
+	return fmt.Sprintf("%s %T", info.Kind, message)
+}
+
+func attributes(ctx context.Context, info cqrs.DispatchInfo, message interface{}) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{
+		MessageKindKey.String(string(info.Kind)),
+		MessageTypeKey.String(fmt.Sprintf("%T", message)),
+		HandlerTypeKey.String(info.HandlerType),
+		AsyncKey.Bool(info.Async),
+	}
+
+	ids := cqrs.MessageIDsFromContext(ctx)
+
+	if ids.MessageID != "" {
+		attrs = append(attrs, MessageIDKey.String(ids.MessageID), CorrelationIDKey.String(ids.CorrelationID))
+	}
+
+	if ids.CausationID != "" {
+		attrs = append(attrs, CausationIDKey.String(ids.CausationID))
+	}
+
+	return attrs
+}
+
+func endSpan(span trace.Span, err *error) {
+	if p := recover(); p != nil {
+		span.RecordError(fmt.Errorf("panic: %v", p), trace.WithStackTrace(true))
+		span.SetStatus(codes.Error, "panic")
+		span.SetAttributes(OutcomeKey.String(cqrs.OutcomePanic))
+		span.End()
+		panic(p)
+	}
+
+	if *err != nil {
+		span.RecordError(*err)
+		span.SetStatus(codes.Error, (*err).Error())
+		span.SetAttributes(OutcomeKey.String(cqrs.OutcomeError))
+	} else {
+		span.SetAttributes(OutcomeKey.String(cqrs.OutcomeSuccess))
+	}
+
+	span.End()
+}
@@ -0,0 +1,216 @@
+package otelcqrs
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	cqrs "github.com/mitz-it/golang-cqrs"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+type CreateProduct struct {
+	Fail  bool
+	Panic bool
+}
+
+type Product struct {
+}
+
+type CreateProductHandler struct {
+}
+
+func (h *CreateProductHandler) Handle(ctx context.Context, command *CreateProduct) (*Product, error) {
+	if command.Panic {
+		panic("boom")
+	}
+
+	if command.Fail {
+		return nil, errors.New("failed")
+	}
+
+	return &Product{}, nil
+}
+
+type GetProduct struct {
+}
+
+type GetProductHandler struct {
+}
+
+func (h *GetProductHandler) Handle(ctx context.Context, query *GetProduct) (*Product, error) {
+	return &Product{}, nil
+}
+
+type ProductCreated struct {
+}
+
+type ProductCreatedHandler struct {
+	done chan struct{}
+}
+
+func (h *ProductCreatedHandler) Handle(ctx context.Context, event *ProductCreated) error {
+	if h.done != nil {
+		h.done <- struct{}{}
+	}
+	return nil
+}
+
+type ProductDeleted struct {
+}
+
+type ProductDeletedHandler struct {
+}
+
+func (h *ProductDeletedHandler) Handle(ctx context.Context, event *ProductDeleted) error {
+	return nil
+}
+
+var exporter *tracetest.InMemoryExporter
+var provider *sdktrace.TracerProvider
+var productCreated *ProductCreatedHandler
+
+func TestMain(m *testing.M) {
+	exporter = tracetest.NewInMemoryExporter()
+	provider = sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	productCreated = &ProductCreatedHandler{done: make(chan struct{}, 1)}
+
+	cqrs.RegisterCommandHandler[*CreateProduct, *Product](&CreateProductHandler{})
+	cqrs.RegisterQueryHandler[*GetProduct, *Product](&GetProductHandler{})
+	cqrs.RegisterEventSubscriber[*ProductCreated](productCreated)
+	cqrs.RegisterEventSubscriber[*ProductDeleted](&ProductDeletedHandler{})
+	cqrs.RegisterCommandPipelineBehavior(0, NewTracingBehavior(WithTracerProvider(provider)))
+	cqrs.RegisterQueryPipelineBehavior(0, NewTracingBehavior(WithTracerProvider(provider)))
+	cqrs.RegisterEventBehavior(0, NewEventTracingBehavior(WithTracerProvider(provider)))
+	cqrs.Listen()
+
+	os.Exit(m.Run())
+}
+
+func attributeValue(span tracetest.SpanStub, key attribute.Key) attribute.Value {
+	for _, attr := range span.Attributes {
+		if attr.Key == key {
+			return attr.Value
+		}
+	}
+
+	return attribute.Value{}
+}
+
+func TestTracingBehavior_WhenCommandSucceeds_ShouldRecordSpan(t *testing.T) {
+	// arrange
+	exporter.Reset()
+
+	// act
+	_, err := cqrs.Send[*CreateProduct, *Product](context.TODO(), &CreateProduct{})
+
+	// assert
+	spans := exporter.GetSpans()
+	assert.Nil(t, err)
+	assert.Len(t, spans, 1)
+	assert.Equal(t, "command *otelcqrs.CreateProduct", spans[0].Name)
+	assert.Equal(t, "command", attributeValue(spans[0], MessageKindKey).AsString())
+	assert.Equal(t, "*otelcqrs.CreateProductHandler", attributeValue(spans[0], HandlerTypeKey).AsString())
+	assert.Equal(t, cqrs.OutcomeSuccess, attributeValue(spans[0], OutcomeKey).AsString())
+}
+
+func TestTracingBehavior_WhenQuerySucceeds_ShouldRecordSpan(t *testing.T) {
+	// arrange
+	exporter.Reset()
+
+	// act
+	cqrs.Request[*GetProduct, *Product](context.TODO(), &GetProduct{})
+
+	// assert
+	spans := exporter.GetSpans()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, "query *otelcqrs.GetProduct", spans[0].Name)
+}
+
+func TestTracingBehavior_WhenCommandFails_ShouldRecordError(t *testing.T) {
+	// arrange
+	exporter.Reset()
+
+	// act
+	cqrs.Send[*CreateProduct, *Product](context.TODO(), &CreateProduct{Fail: true})
+
+	// assert
+	spans := exporter.GetSpans()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, codes.Error, spans[0].Status.Code)
+	assert.Equal(t, cqrs.OutcomeError, attributeValue(spans[0], OutcomeKey).AsString())
+	assert.Len(t, spans[0].Events, 1)
+}
+
+func TestTracingBehavior_WhenHandlerPanics_ShouldRecordPanicAndPanic(t *testing.T) {
+	// arrange
+	exporter.Reset()
+
+	// act
+	send := func() {
+		cqrs.Send[*CreateProduct, *Product](context.TODO(), &CreateProduct{Panic: true})
+	}
+
+	// assert
+	assert.PanicsWithValue(t, "boom", send)
+	spans := exporter.GetSpans()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, cqrs.OutcomePanic, attributeValue(spans[0], OutcomeKey).AsString())
+}
+
+func TestEventTracingBehavior_WhenPublishingEvent_ShouldRecordChildSpan(t *testing.T) {
+	// arrange
+	exporter.Reset()
+	ctx, parent := provider.Tracer("test").Start(context.TODO(), "parent")
+
+	// act
+	cqrs.PublishEvent(ctx, &ProductDeleted{})
+	parent.End()
+
+	// assert
+	spans := exporter.GetSpans()
+	assert.Len(t, spans, 2)
+	assert.Equal(t, "event *otelcqrs.ProductDeleted", spans[0].Name)
+	assert.Equal(t, parent.SpanContext().SpanID(), spans[0].Parent.SpanID())
+	assert.False(t, attributeValue(spans[0], AsyncKey).AsBool())
+}
+
+func TestEventTracingBehavior_WhenPublishingEventAsync_ShouldLinkToPublishingSpan(t *testing.T) {
+	// arrange
+	exporter.Reset()
+	ctx, parent := provider.Tracer("test").Start(context.TODO(), "parent")
+
+	// act
+	cqrs.PublishEventAsync(ctx, &ProductCreated{})
+	parent.End()
+
+	// assert
+	select {
+	case <-productCreated.done:
+	case <-time.After(time.Second):
+		t.Fatal("async handler was not called")
+	}
+
+	assert.Eventually(t, func() bool {
+		return len(exporter.GetSpans()) == 2
+	}, time.Second, 10*time.Millisecond)
+
+	var eventSpan tracetest.SpanStub
+
+	for _, span := range exporter.GetSpans() {
+		if span.Name == "event *otelcqrs.ProductCreated" {
+			eventSpan = span
+		}
+	}
+
+	assert.False(t, eventSpan.Parent.IsValid())
+	assert.Len(t, eventSpan.Links, 1)
+	assert.Equal(t, parent.SpanContext().SpanID(), eventSpan.Links[0].SpanContext.SpanID())
+	assert.True(t, attributeValue(eventSpan, AsyncKey).AsBool())
+}
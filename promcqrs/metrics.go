@@ -0,0 +1,93 @@
+package promcqrs
+
+import (
+	"context"
+	"strconv"
+
+	cqrs "github.com/mitz-it/golang-cqrs"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var labels = []string{"kind", "message_type", "handler_type", "async", "outcome"}
+
+type Metrics struct {
+	dispatches  *prometheus.CounterVec
+	duration    *prometheus.HistogramVec
+	queueDepth  prometheus.Gauge
+	busyWorkers prometheus.Gauge
+	utilization prometheus.Gauge
+}
+
+func NewMetrics(registerer prometheus.Registerer, namespace string) (*Metrics, error) {
+	m := &Metrics{
+		dispatches: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "cqrs",
+			Name:      "dispatch_total",
+			Help:      "Number of dispatched commands, queries and events.",
+		}, labels),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "cqrs",
+			Name:      "dispatch_duration_seconds",
+			Help:      "Duration of dispatched commands, queries and events.",
+			Buckets:   prometheus.DefBuckets,
+		}, labels),
+		queueDepth: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "cqrs",
+			Name:      "async_queue_depth",
+			Help:      "Number of events waiting for an async listener.",
+		}),
+		busyWorkers: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "cqrs",
+			Name:      "async_workers_busy",
+			Help:      "Number of async listeners handling an event.",
+		}),
+		utilization: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "cqrs",
+			Name:      "async_workers_utilization_ratio",
+			Help:      "Ratio of busy async listeners to running async listeners.",
+		}),
+	}
+
+	collectors := []prometheus.Collector{m.dispatches, m.duration, m.queueDepth, m.busyWorkers, m.utilization}
+
+	for _, collector := range collectors {
+		if err := registerer.Register(collector); err != nil {
+			return nil, err
+		}
+	}
+
+	return m, nil
+}
+
+func (m *Metrics) RecordDispatch(ctx context.Context, dispatch cqrs.DispatchMetric) {
+	values := []string{
+		string(dispatch.Kind),
+		dispatch.MessageType,
+		dispatch.HandlerType,
+		strconv.FormatBool(dispatch.Async),
+		dispatch.Outcome,
+	}
+
+	m.dispatches.WithLabelValues(values...).Inc()
+	m.duration.WithLabelValues(values...).Observe(dispatch.Duration.Seconds())
+}
+
+func (m *Metrics) RecordAsyncQueueDepth(depth int64) {
+	m.queueDepth.Set(float64(depth))
+}
+
+func (m *Metrics) RecordAsyncWorkers(busy int64, total int64) {
+	m.busyWorkers.Set(float64(busy))
+
+	if total <= 0 {
+		m.utilization.Set(0)
+		return
+	}
+
+	m.utilization.Set(float64(busy) / float64(total))
+}
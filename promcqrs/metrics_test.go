@@ -0,0 +1,94 @@
+package promcqrs
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	cqrs "github.com/mitz-it/golang-cqrs"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+type CreateProduct struct {
+	Fail bool
+}
+
+type Product struct {
+}
+
+type CreateProductHandler struct {
+}
+
+func (h *CreateProductHandler) Handle(ctx context.Context, command *CreateProduct) (*Product, error) {
+	if command.Fail {
+		return nil, errors.New("failed")
+	}
+
+	return &Product{}, nil
+}
+
+func TestMain(m *testing.M) {
+	cqrs.RegisterCommandHandler[*CreateProduct, *Product](&CreateProductHandler{})
+
+	os.Exit(m.Run())
+}
+
+func TestMetrics_WhenCommandDispatched_ShouldCountByOutcome(t *testing.T) {
+	// arrange
+	registry := prometheus.NewRegistry()
+	m, err := NewMetrics(registry, "app")
+	assert.Nil(t, err)
+	cqrs.SetMetrics(m)
+	defer cqrs.SetMetrics(nil)
+
+	// act
+	cqrs.Send[*CreateProduct, *Product](context.TODO(), &CreateProduct{})
+	cqrs.Send[*CreateProduct, *Product](context.TODO(), &CreateProduct{})
+	cqrs.Send[*CreateProduct, *Product](context.TODO(), &CreateProduct{Fail: true})
+
+	// assert
+	success := m.dispatches.WithLabelValues("command", "*promcqrs.CreateProduct", "*promcqrs.CreateProductHandler", "false", cqrs.OutcomeSuccess)
+	failure := m.dispatches.WithLabelValues("command", "*promcqrs.CreateProduct", "*promcqrs.CreateProductHandler", "false", cqrs.OutcomeError)
+	assert.Equal(t, 2.0, testutil.ToFloat64(success))
+	assert.Equal(t, 1.0, testutil.ToFloat64(failure))
+	assert.Equal(t, 2, testutil.CollectAndCount(m.duration, "app_cqrs_dispatch_duration_seconds"))
+}
+
+func TestMetrics_WhenAsyncStatsRecorded_ShouldSetGauges(t *testing.T) {
+	// arrange
+	registry := prometheus.NewRegistry()
+	m, _ := NewMetrics(registry, "app")
+
+	// act
+	m.RecordAsyncQueueDepth(3)
+	m.RecordAsyncWorkers(1, 4)
+
+	// assert
+	expected := `
+# HELP app_cqrs_async_queue_depth Number of events waiting for an async listener.
+# TYPE app_cqrs_async_queue_depth gauge
+app_cqrs_async_queue_depth 3
+# HELP app_cqrs_async_workers_utilization_ratio Ratio of busy async listeners to running async listeners.
+# TYPE app_cqrs_async_workers_utilization_ratio gauge
+app_cqrs_async_workers_utilization_ratio 0.25
+`
+	err := testutil.GatherAndCompare(registry, strings.NewReader(expected), "app_cqrs_async_queue_depth", "app_cqrs_async_workers_utilization_ratio")
+	assert.Nil(t, err)
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.busyWorkers))
+}
+
+func TestNewMetrics_WhenRegisteredTwice_ShouldReturnError(t *testing.T) {
+	// arrange
+	registry := prometheus.NewRegistry()
+	NewMetrics(registry, "app")
+
+	// act
+	_, err := NewMetrics(registry, "app")
+
+	// assert
+	assert.Error(t, err)
+}
@@ -5,8 +5,6 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
-
-	"github.com/ahmetb/go-linq/v3"
 )
 
 type IQueryHandler[TQuery any, TResponse any] interface {
@@ -52,26 +50,25 @@ func Request[TQuery any, TResponse any](ctx context.Context, query TQuery) (TRes
 		return *new(TResponse), errors.New(msg)
 	}
 
-	if len(queryBehaviors) <= 0 {
-		return handler.Handle(ctx, query)
-	}
+	queryHandle := func(ctx context.Context, request interface{}) (interface{}, error) {
+		query, casted := request.(TQuery)
 
-	sortedBehaviors := sortBehaviors(queryBehaviors)
+		if !casted {
+			msg := fmt.Sprintf("query of type %T can't be replaced by a request of type %T", *new(TQuery), request)
+			return nil, errors.New(msg)
+		}
 
-	queryHandle := func() (interface{}, error) {
 		return handler.Handle(ctx, query)
 	}
 
-	aggregatedPipeline := linq.From(sortedBehaviors).AggregateWithSeedT(queryHandle, func(next NextFunc, b IBehavior) NextFunc {
-		var nextFunc NextFunc = func() (interface{}, error) {
-			return b.Handle(ctx, query, next)
-		}
-		return nextFunc
-	})
-
-	pipeline := aggregatedPipeline.(NextFunc)
+	info := DispatchInfo{
+		Kind:         QueryMessage,
+		MessageType:  queryType.String(),
+		HandlerType:  handlerName(h),
+		responseType: reflect.TypeOf(new(TResponse)).Elem(),
+	}
 
-	res, err := pipeline()
+	res, err := dispatch(ctx, info, query, queryBehaviors, queryHandle)
 
 	response, casted := res.(TResponse)
 
@@ -98,6 +98,7 @@ func TestRequest_WhenNoQueryBehaviors_ShouldCallHandler(t *testing.T) {
 func TestRequest_WhenHaveQueryBehaviors_ShouldCallHandlerThroughPipeline(t *testing.T) {
 	// arrange
 	defer querys_cleanup(t)
+	defer behaviors_cleanup(t)
 	query := &Query1{}
 	handler := &QueryHandler1{}
 	RegisterQueryBehavior(0, &Behavior1{})
@@ -115,6 +116,7 @@ func TestRequest_WhenHaveQueryBehaviors_ShouldCallHandlerThroughPipeline(t *test
 func TestRequest_WhenHaveQueryBehaviorsAndResponseCanBeCasted_ShouldDefaultResponse(t *testing.T) {
 	// arrange
 	defer querys_cleanup(t)
+	defer behaviors_cleanup(t)
 	query := &Query1{}
 	handler := &QueryHandler1{}
 	RegisterQueryBehavior(0, &Behavior3{})
@@ -125,5 +127,35 @@ func TestRequest_WhenHaveQueryBehaviorsAndResponseCanBeCasted_ShouldDefaultRespo
 
 	// assert
 	assert.Nil(t, err)
-	assert.Equal(t, &Response{}, res)
+	assert.Nil(t, res)
+}
+
+type EnrichedQuery struct {
+	Normalized bool
+}
+
+type EnrichedQueryHandler struct {
+	received *EnrichedQuery
+}
+
+func (h *EnrichedQueryHandler) Handle(ctx context.Context, query *EnrichedQuery) (*Response, error) {
+	h.received = query
+	return &Response{}, nil
+}
+
+func TestRequest_WhenBehaviorReplacesQuery_ShouldPassNewQueryToHandler(t *testing.T) {
+	// arrange
+	defer querys_cleanup(t)
+	defer behaviors_cleanup(t)
+	handler := &EnrichedQueryHandler{}
+	normalized := &EnrichedQuery{Normalized: true}
+	RegisterQueryPipelineBehavior(0, &ReplacingBehavior{request: normalized})
+	RegisterQueryHandler[*EnrichedQuery, *Response](handler)
+
+	// act
+	_, err := Request[*EnrichedQuery, *Response](context.TODO(), &EnrichedQuery{})
+
+	// assert
+	assert.Nil(t, err)
+	assert.Same(t, normalized, handler.received)
 }
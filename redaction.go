@@ -0,0 +1,137 @@
+package cqrs
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+const RedactedValue = "[REDACTED]"
+
+const maxRedactionDepth = 32
+
+func Redact(value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+
+	return redactValue(reflect.ValueOf(value), 0)
+}
+
+func redactValue(value reflect.Value, depth int) interface{} {
+	if depth > maxRedactionDepth {
+		return nil
+	}
+
+	switch value.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if value.IsNil() {
+			return nil
+		}
+		return redactValue(value.Elem(), depth+1)
+	case reflect.Struct:
+		if marshals(value) {
+			return value.Interface()
+		}
+		return redactStruct(value, depth)
+	case reflect.Map:
+		if value.IsNil() {
+			return nil
+		}
+		redacted := make(map[string]interface{}, value.Len())
+		iter := value.MapRange()
+		for iter.Next() {
+			redacted[fmt.Sprint(iter.Key().Interface())] = redactValue(iter.Value(), depth+1)
+		}
+		return redacted
+	case reflect.Slice:
+		if value.IsNil() {
+			return nil
+		}
+		if value.Type().Elem().Kind() == reflect.Uint8 {
+			return value.Interface()
+		}
+		return redactSlice(value, depth)
+	case reflect.Array:
+		return redactSlice(value, depth)
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		return nil
+	}
+
+	return value.Interface()
+}
+
+func redactStruct(value reflect.Value, depth int) interface{} {
+	valueType := value.Type()
+	redacted := make(map[string]interface{}, value.NumField())
+
+	for i := 0; i < value.NumField(); i++ {
+		field := valueType.Field(i)
+
+		if !field.IsExported() {
+			continue
+		}
+
+		name, skip := fieldName(field)
+
+		if skip {
+			continue
+		}
+
+		if isSensitive(field) {
+			redacted[name] = RedactedValue
+			continue
+		}
+
+		redacted[name] = redactValue(value.Field(i), depth+1)
+	}
+
+	return redacted
+}
+
+func marshals(value reflect.Value) bool {
+	switch value.Interface().(type) {
+	case json.Marshaler, encoding.TextMarshaler:
+		return true
+	}
+
+	return false
+}
+
+func redactSlice(value reflect.Value, depth int) interface{} {
+	redacted := make([]interface{}, value.Len())
+
+	for i := 0; i < value.Len(); i++ {
+		redacted[i] = redactValue(value.Index(i), depth+1)
+	}
+
+	return redacted
+}
+
+func fieldName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("json")
+
+	if tag == "-" {
+		return "", true
+	}
+
+	name, _, _ := strings.Cut(tag, ",")
+
+	if name == "" {
+		return field.Name, false
+	}
+
+	return name, false
+}
+
+func isSensitive(field reflect.StructField) bool {
+	for _, option := range strings.Split(field.Tag.Get("cqrs"), ",") {
+		if strings.TrimSpace(option) == "sensitive" {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,94 @@
+package cqrs
+
+import (
+	"reflect"
+
+	"go.uber.org/multierr"
+)
+
+type Registration func() error
+
+func CommandHandler[TCommand any, TResponse any](handler ICommandHandler[TCommand, TResponse]) Registration {
+	return func() error {
+		return RegisterCommandHandler[TCommand, TResponse](handler)
+	}
+}
+
+func VoidCommandHandler[TCommand any](handler IVoidCommandHandler[TCommand]) Registration {
+	return func() error {
+		return RegisterVoidCommandHandler[TCommand](handler)
+	}
+}
+
+func QueryHandler[TQuery any, TResponse any](handler IQueryHandler[TQuery, TResponse]) Registration {
+	return func() error {
+		return RegisterQueryHandler[TQuery, TResponse](handler)
+	}
+}
+
+func StreamQueryHandler[TQuery any, TItem any](handler IStreamQueryHandler[TQuery, TItem]) Registration {
+	return func() error {
+		return RegisterStreamQueryHandler[TQuery, TItem](handler)
+	}
+}
+
+func EventSubscriber[TEvent any](handler IEventHandler[TEvent]) Registration {
+	return func() error {
+		return RegisterEventSubscriber[TEvent](handler)
+	}
+}
+
+func CommandBehavior(order int, behavior IPipelineBehavior) Registration {
+	return func() error {
+		return RegisterCommandPipelineBehavior(order, behavior)
+	}
+}
+
+func QueryBehavior(order int, behavior IPipelineBehavior) Registration {
+	return func() error {
+		return RegisterQueryPipelineBehavior(order, behavior)
+	}
+}
+
+func EventBehavior(order int, behavior IEventBehavior) Registration {
+	return func() error {
+		return RegisterEventBehavior(order, behavior)
+	}
+}
+
+func StreamBehavior(order int, behavior IStreamBehavior) Registration {
+	return func() error {
+		return RegisterStreamBehavior(order, behavior)
+	}
+}
+
+func Register(registrations ...Registration) error {
+	var err error
+
+	for _, registration := range registrations {
+		if registration == nil {
+			continue
+		}
+
+		err = multierr.Append(err, registration())
+	}
+
+	return err
+}
+
+// registries are global, so tests and applications that register more than
+// once per process reset them in between
+func Reset() {
+	commandHandlers = make(map[reflect.Type]interface{})
+	batchCommandHandlers = make(map[reflect.Type]interface{})
+	queryHandlers = make(map[reflect.Type]interface{})
+	batchQueryHandlers = make(map[reflect.Type]interface{})
+	streamQueryHandlers = make(map[reflect.Type]interface{})
+	eventHandlers = make(map[reflect.Type][]interface{})
+	commandBehaviors = make(map[int]interface{})
+	queryBehaviors = make(map[int]interface{})
+	eventBehaviors = make(map[int]interface{})
+	streamBehaviors = make(map[int]interface{})
+	validators = make(map[reflect.Type][]interface{})
+	authorizationPolicies = make(map[reflect.Type][]interface{})
+}
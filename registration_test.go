@@ -0,0 +1,73 @@
+package cqrs
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegister_WhenRegistrationsProvided_ShouldRegisterEach(t *testing.T) {
+	// arrange
+	defer commands_cleanup(t)
+	defer querys_cleanup(t)
+	defer events_cleanup(t)
+	defer behaviors_cleanup(t)
+	behavior := &CountingBehavior{}
+
+	// act
+	err := Register(
+		CommandHandler[*Command1, *Response](&CommandHandler1{}),
+		QueryHandler[*Query1, *Response](&QueryHandler1{}),
+		EventSubscriber[*FakeEvent](&FakeEventHandler1{}),
+		CommandBehavior(0, behavior),
+	)
+
+	// assert
+	_, sendErr := Send[*Command1, *Response](context.TODO(), &Command1{})
+	_, requestErr := Request[*Query1, *Response](context.TODO(), &Query1{})
+	assert.Nil(t, err)
+	assert.Nil(t, sendErr)
+	assert.Nil(t, requestErr)
+	assert.Len(t, eventHandlers[reflect.TypeOf(&FakeEvent{})], 1)
+	assert.Len(t, behavior.requests, 1)
+}
+
+func TestRegister_WhenRegistrationsFail_ShouldReturnAllErrors(t *testing.T) {
+	// arrange
+	defer commands_cleanup(t)
+	defer querys_cleanup(t)
+
+	// act
+	err := Register(
+		CommandHandler[*Command1, *Response](&CommandHandler1{}),
+		CommandHandler[*Command1, *Response](&CommandHandler1{}),
+		QueryHandler[*Query1, *Response](&QueryHandler1{}),
+		QueryHandler[*Query1, *Response](&QueryHandler1{}),
+	)
+
+	// assert
+	assert.EqualError(t, err, "handler for command of type *cqrs.Command1 is already registered; handler for query of type *cqrs.Query1 is already registered")
+}
+
+func TestReset_WhenRegistered_ShouldAllowRegisteringAgain(t *testing.T) {
+	// arrange
+	defer Reset()
+	Register(
+		CommandHandler[*Command1, *Response](&CommandHandler1{}),
+		EventSubscriber[*FakeEvent](&FakeEventHandler1{}),
+		CommandBehavior(0, &CountingBehavior{}),
+	)
+
+	// act
+	Reset()
+	err := Register(
+		CommandHandler[*Command1, *Response](&CommandHandler1{}),
+		CommandBehavior(0, &CountingBehavior{}),
+	)
+
+	// assert
+	assert.Nil(t, err)
+	assert.Empty(t, eventHandlers)
+}
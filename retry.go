@@ -0,0 +1,190 @@
+package cqrs
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"reflect"
+	"time"
+)
+
+type Retryable interface {
+	Retryable() bool
+}
+
+type RetryClassifier func(err error) bool
+
+type BackoffFunc func(attempt int) time.Duration
+
+func ConstantBackoff(delay time.Duration) BackoffFunc {
+	return func(attempt int) time.Duration {
+		return delay
+	}
+}
+
+func ExponentialBackoff(base time.Duration, max time.Duration) BackoffFunc {
+	return func(attempt int) time.Duration {
+		delay := base
+
+		for i := 1; i < attempt; i++ {
+			delay *= 2
+
+			if max > 0 && delay >= max {
+				return max
+			}
+		}
+
+		return delay
+	}
+}
+
+func IsRetryable(err error) bool {
+	var retryable Retryable
+
+	if errors.As(err, &retryable) {
+		return retryable.Retryable()
+	}
+
+	return false
+}
+
+type RetryPolicy struct {
+	MaxAttempts int
+	Backoff     BackoffFunc
+	Jitter      float64
+	Classifier  RetryClassifier
+}
+
+type RetryHook func(ctx context.Context, request interface{}, attempt int, err error, delay time.Duration)
+
+type RetryCompletedHook func(ctx context.Context, request interface{}, attempts int, err error)
+
+type RetryOption func(behavior *RetryBehavior)
+
+func RetryPolicyFor[TRequest any](policy RetryPolicy) RetryOption {
+	var request TRequest
+	requestType := reflect.TypeOf(request)
+
+	return func(behavior *RetryBehavior) {
+		behavior.policies[requestType] = policy
+	}
+}
+
+func OnRetry(hook RetryHook) RetryOption {
+	return func(behavior *RetryBehavior) {
+		behavior.onRetry = hook
+	}
+}
+
+func OnRetryCompleted(hook RetryCompletedHook) RetryOption {
+	return func(behavior *RetryBehavior) {
+		behavior.onCompleted = hook
+	}
+}
+
+type RetryBehavior struct {
+	policy      RetryPolicy
+	policies    map[reflect.Type]RetryPolicy
+	onRetry     RetryHook
+	onCompleted RetryCompletedHook
+	sleep       func(ctx context.Context, delay time.Duration) error
+}
+
+func NewRetryBehavior(policy RetryPolicy, options ...RetryOption) *RetryBehavior {
+	behavior := &RetryBehavior{
+		policy:   policy,
+		policies: make(map[reflect.Type]RetryPolicy),
+		sleep:    sleep,
+	}
+
+	for _, option := range options {
+		option(behavior)
+	}
+
+	return behavior
+}
+
+func (b *RetryBehavior) Handle(ctx context.Context, request interface{}, next PipelineFunc) (interface{}, error) {
+	policy := b.policyFor(request)
+
+	attempt := 1
+	res, err := next(ctx, request)
+
+	for err != nil && attempt < policy.MaxAttempts && policy.classify(err) {
+		delay := policy.delay(attempt)
+
+		if b.onRetry != nil {
+			b.onRetry(ctx, request, attempt, err, delay)
+		}
+
+		sleepErr := b.sleep(ctx, delay)
+
+		if sleepErr != nil {
+			err = sleepErr
+			break
+		}
+
+		attempt++
+		res, err = next(ctx, request)
+	}
+
+	if b.onCompleted != nil {
+		b.onCompleted(ctx, request, attempt, err)
+	}
+
+	return res, err
+}
+
+func (b *RetryBehavior) policyFor(request interface{}) RetryPolicy {
+	policy, found := b.policies[reflect.TypeOf(request)]
+
+	if found {
+		return policy
+	}
+
+	return b.policy
+}
+
+func (p RetryPolicy) classify(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	if p.Classifier != nil {
+		return p.Classifier(err)
+	}
+
+	return IsRetryable(err)
+}
+
+func (p RetryPolicy) delay(attempt int) time.Duration {
+	if p.Backoff == nil {
+		return 0
+	}
+
+	delay := p.Backoff(attempt)
+
+	if p.Jitter > 0 && delay > 0 {
+		jitter := math.Min(p.Jitter, 1)
+		delay -= time.Duration(rand.Float64() * jitter * float64(delay))
+	}
+
+	return delay
+}
+
+func sleep(ctx context.Context, delay time.Duration) error {
+	if delay <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
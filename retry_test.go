@@ -0,0 +1,205 @@
+package cqrs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type ConflictError struct {
+}
+
+func (e *ConflictError) Error() string {
+	return "concurrency conflict"
+}
+
+func (e *ConflictError) Retryable() bool {
+	return true
+}
+
+type FlakyCommandHandler struct {
+	failures int
+	calls    int
+}
+
+func (h *FlakyCommandHandler) Handle(ctx context.Context, command *Command1) (*Response, error) {
+	h.calls++
+
+	if h.calls <= h.failures {
+		return nil, &ConflictError{}
+	}
+
+	return &Response{}, nil
+}
+
+func noSleep(ctx context.Context, delay time.Duration) error {
+	return nil
+}
+
+func TestRetryBehavior_WhenErrorIsRetryable_ShouldRetryUntilSuccess(t *testing.T) {
+	// arrange
+	defer commands_cleanup(t)
+	defer behaviors_cleanup(t)
+	handler := &FlakyCommandHandler{failures: 2}
+	behavior := NewRetryBehavior(RetryPolicy{MaxAttempts: 3})
+	behavior.sleep = noSleep
+	RegisterCommandHandler[*Command1, *Response](handler)
+	RegisterCommandPipelineBehavior(0, behavior)
+
+	// act
+	res, err := Send[*Command1, *Response](context.TODO(), &Command1{})
+
+	// assert
+	assert.Nil(t, err)
+	assert.Equal(t, &Response{}, res)
+	assert.Equal(t, 3, handler.calls)
+}
+
+func TestRetryBehavior_WhenAttemptsExhausted_ShouldReturnLastError(t *testing.T) {
+	// arrange
+	handler := &FlakyCommandHandler{failures: 5}
+	attempts := 0
+	behavior := NewRetryBehavior(RetryPolicy{MaxAttempts: 3}, OnRetryCompleted(func(ctx context.Context, request interface{}, n int, err error) {
+		attempts = n
+	}))
+	behavior.sleep = noSleep
+	next := func(ctx context.Context, request interface{}) (interface{}, error) {
+		return handler.Handle(ctx, request.(*Command1))
+	}
+
+	// act
+	_, err := behavior.Handle(context.TODO(), &Command1{}, next)
+
+	// assert
+	var conflict *ConflictError
+	assert.True(t, errors.As(err, &conflict))
+	assert.Equal(t, 3, handler.calls)
+	assert.Equal(t, 3, attempts)
+}
+
+func TestRetryBehavior_WhenErrorIsNotRetryable_ShouldNotRetry(t *testing.T) {
+	// arrange
+	behavior := NewRetryBehavior(RetryPolicy{MaxAttempts: 3})
+	behavior.sleep = noSleep
+	calls := 0
+	next := func(ctx context.Context, request interface{}) (interface{}, error) {
+		calls++
+		return nil, errors.New("not found")
+	}
+
+	// act
+	_, err := behavior.Handle(context.TODO(), &Command1{}, next)
+
+	// assert
+	assert.Error(t, err)
+	assert.Equal(t, 1, calls)
+}
+
+func TestRetryBehavior_WhenClassifierProvided_ShouldUseClassifier(t *testing.T) {
+	// arrange
+	deadlock := errors.New("deadlock detected")
+	policy := RetryPolicy{
+		MaxAttempts: 2,
+		Classifier: func(err error) bool {
+			return errors.Is(err, deadlock)
+		},
+	}
+	behavior := NewRetryBehavior(policy)
+	behavior.sleep = noSleep
+	calls := 0
+	next := func(ctx context.Context, request interface{}) (interface{}, error) {
+		calls++
+		return nil, deadlock
+	}
+
+	// act
+	behavior.Handle(context.TODO(), &Command1{}, next)
+
+	// assert
+	assert.Equal(t, 2, calls)
+}
+
+func TestRetryBehavior_WhenPolicyForTypeRegistered_ShouldUseTypePolicy(t *testing.T) {
+	// arrange
+	behavior := NewRetryBehavior(RetryPolicy{MaxAttempts: 1}, RetryPolicyFor[*Command1](RetryPolicy{MaxAttempts: 4}))
+	behavior.sleep = noSleep
+	calls := 0
+	next := func(ctx context.Context, request interface{}) (interface{}, error) {
+		calls++
+		return nil, &ConflictError{}
+	}
+
+	// act
+	behavior.Handle(context.TODO(), &Command1{}, next)
+	behavior.Handle(context.TODO(), &Query1{}, next)
+
+	// assert
+	assert.Equal(t, 5, calls)
+}
+
+func TestRetryBehavior_WhenRetrying_ShouldReportAttemptsAndDelays(t *testing.T) {
+	// arrange
+	var attempts []int
+	var delays []time.Duration
+	policy := RetryPolicy{
+		MaxAttempts: 3,
+		Backoff:     ExponentialBackoff(10*time.Millisecond, time.Second),
+	}
+	behavior := NewRetryBehavior(policy, OnRetry(func(ctx context.Context, request interface{}, attempt int, err error, delay time.Duration) {
+		attempts = append(attempts, attempt)
+		delays = append(delays, delay)
+	}))
+	behavior.sleep = noSleep
+	next := func(ctx context.Context, request interface{}) (interface{}, error) {
+		return nil, &ConflictError{}
+	}
+
+	// act
+	behavior.Handle(context.TODO(), &Command1{}, next)
+
+	// assert
+	assert.Equal(t, []int{1, 2}, attempts)
+	assert.Equal(t, []time.Duration{10 * time.Millisecond, 20 * time.Millisecond}, delays)
+}
+
+func TestRetryBehavior_WhenContextCanceledWhileWaiting_ShouldReturnContextError(t *testing.T) {
+	// arrange
+	behavior := NewRetryBehavior(RetryPolicy{MaxAttempts: 3, Backoff: ConstantBackoff(time.Minute)})
+	ctx, cancel := context.WithCancel(context.TODO())
+	next := func(ctx context.Context, request interface{}) (interface{}, error) {
+		cancel()
+		return nil, &ConflictError{}
+	}
+
+	// act
+	_, err := behavior.Handle(ctx, &Command1{}, next)
+
+	// assert
+	assert.True(t, errors.Is(err, context.Canceled))
+}
+
+func TestExponentialBackoff_WhenDelayExceedsMax_ShouldReturnMax(t *testing.T) {
+	// arrange
+	backoff := ExponentialBackoff(time.Second, 5*time.Second)
+
+	// act
+	delay := backoff(10)
+
+	// assert
+	assert.Equal(t, 5*time.Second, delay)
+}
+
+func TestRetryPolicy_WhenJitterConfigured_ShouldNotExceedBackoff(t *testing.T) {
+	// arrange
+	policy := RetryPolicy{Backoff: ConstantBackoff(time.Second), Jitter: 0.5}
+
+	// act
+	delay := policy.delay(1)
+
+	// assert
+	assert.LessOrEqual(t, delay, time.Second)
+	assert.GreaterOrEqual(t, delay, 500*time.Millisecond)
+}
@@ -0,0 +1,248 @@
+package cqrs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/ahmetb/go-linq/v3"
+)
+
+type IStreamQueryHandler[TQuery any, TItem any] interface {
+	Handle(ctx context.Context, query TQuery, yield func(item TItem) error) error
+}
+
+type StreamEmitFunc func(ctx context.Context, item interface{}) error
+
+type StreamFunc func(ctx context.Context, query interface{}, emit StreamEmitFunc) error
+
+type IStreamBehavior interface {
+	Handle(ctx context.Context, query interface{}, emit StreamEmitFunc, next StreamFunc) error
+}
+
+var streamQueryHandlers map[reflect.Type]interface{}
+var streamBehaviors map[int]interface{}
+
+func init() {
+	streamQueryHandlers = make(map[reflect.Type]interface{})
+	streamBehaviors = make(map[int]interface{})
+}
+
+func RegisterStreamQueryHandler[TQuery any, TItem any](handler IStreamQueryHandler[TQuery, TItem]) error {
+	var query TQuery
+	queryType := reflect.TypeOf(query)
+
+	_, found := streamQueryHandlers[queryType]
+
+	if found {
+		msg := fmt.Sprintf("handler for stream query of type %s is already registered", queryType.String())
+		return errors.New(msg)
+	}
+
+	streamQueryHandlers[queryType] = handler
+
+	return nil
+}
+
+func RegisterStreamBehavior(order int, behavior IStreamBehavior) error {
+	_, found := streamBehaviors[order]
+
+	if found {
+		msg := fmt.Sprintf("position %d is taken by another stream behavior.", order)
+		return errors.New(msg)
+	}
+
+	streamBehaviors[order] = behavior
+
+	return nil
+}
+
+type Stream[TItem any] struct {
+	items  chan TItem
+	done   chan struct{}
+	err    error
+	cancel context.CancelFunc
+}
+
+func (s *Stream[TItem]) Items() <-chan TItem {
+	return s.items
+}
+
+func (s *Stream[TItem]) Err() error {
+	<-s.done
+	return s.err
+}
+
+func (s *Stream[TItem]) Close() error {
+	s.cancel()
+
+	for range s.items {
+	}
+
+	err := s.Err()
+
+	if errors.Is(err, context.Canceled) {
+		return nil
+	}
+
+	return err
+}
+
+func RequestStream[TQuery any, TItem any](ctx context.Context, query TQuery) (*Stream[TItem], error) {
+	queryType := reflect.TypeOf(query)
+
+	h, found := streamQueryHandlers[queryType]
+
+	if !found {
+		msg := fmt.Sprintf("no handler registered for stream query %T", query)
+		return nil, errors.New(msg)
+	}
+
+	handler, casted := h.(IStreamQueryHandler[TQuery, TItem])
+
+	if !casted {
+		msg := fmt.Sprintf("handler of type %T is not assignable for stream query of type %T and item of type %T", h, query, *new(TItem))
+		return nil, errors.New(msg)
+	}
+
+	streamHandle := func(ctx context.Context, request interface{}, emit StreamEmitFunc) error {
+		query, casted := request.(TQuery)
+
+		if !casted {
+			msg := fmt.Sprintf("stream query of type %T can't be replaced by a request of type %T", *new(TQuery), request)
+			return errors.New(msg)
+		}
+
+		return handler.Handle(ctx, query, func(item TItem) error {
+			return emit(ctx, item)
+		})
+	}
+
+	info := DispatchInfo{
+		Kind:        QueryMessage,
+		MessageType: queryType.String(),
+		HandlerType: handlerName(h),
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+
+	stream := &Stream[TItem]{
+		items:  make(chan TItem),
+		done:   make(chan struct{}),
+		cancel: cancel,
+	}
+
+	emit := func(ctx context.Context, item interface{}) error {
+		typed, casted := item.(TItem)
+
+		if !casted {
+			msg := fmt.Sprintf("item of type %T can't be streamed as an item of type %T", item, *new(TItem))
+			return errors.New(msg)
+		}
+
+		select {
+		case stream.items <- typed:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	go func() {
+		defer close(stream.done)
+		defer cancel()
+
+		stream.err = runStream(ctx, info, query, streamHandle, emit)
+
+		close(stream.items)
+	}()
+
+	return stream, nil
+}
+
+func runStream(ctx context.Context, info DispatchInfo, query interface{}, handle StreamFunc, emit StreamEmitFunc) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("stream query of type %T panicked: %v", query, p)
+		}
+	}()
+
+	ctx = withDispatchInfo(withMessageIDs(ctx), info)
+
+	defer recordDispatch(ctx, info, time.Now(), &err)
+
+	if len(streamBehaviors) <= 0 {
+		return handle(ctx, query, emit)
+	}
+
+	sortedBehaviors := sortBehaviors(streamBehaviors)
+
+	aggregatedPipeline := linq.From(sortedBehaviors).AggregateWithSeedT(handle, func(next StreamFunc, b IStreamBehavior) StreamFunc {
+		var nextFunc StreamFunc = func(ctx context.Context, query interface{}, emit StreamEmitFunc) error {
+			return b.Handle(ctx, query, emit, next)
+		}
+		return nextFunc
+	})
+
+	pipeline := aggregatedPipeline.(StreamFunc)
+
+	return pipeline(ctx, query, emit)
+}
+
+type StreamItemTimeoutBehavior struct {
+	timeout time.Duration
+}
+
+func NewStreamItemTimeoutBehavior(timeout time.Duration) *StreamItemTimeoutBehavior {
+	return &StreamItemTimeoutBehavior{
+		timeout: timeout,
+	}
+}
+
+func (b *StreamItemTimeoutBehavior) Handle(ctx context.Context, query interface{}, emit StreamEmitFunc, next StreamFunc) error {
+	if b.timeout <= 0 {
+		return next(ctx, query, emit)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var mutex sync.Mutex
+	timedOut := false
+
+	timer := time.AfterFunc(b.timeout, func() {
+		mutex.Lock()
+		timedOut = true
+		mutex.Unlock()
+		cancel()
+	})
+	defer timer.Stop()
+
+	timedEmit := func(ctx context.Context, item interface{}) error {
+		timer.Stop()
+
+		err := emit(ctx, item)
+
+		timer.Reset(b.timeout)
+
+		return err
+	}
+
+	err := next(ctx, query, timedEmit)
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	if timedOut {
+		return &TimeoutError{
+			MessageIDs:  MessageIDsFromContext(ctx),
+			RequestType: reflect.TypeOf(query).String(),
+			Timeout:     b.timeout,
+		}
+	}
+
+	return err
+}
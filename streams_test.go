@@ -0,0 +1,282 @@
+package cqrs
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type ExportProducts struct {
+	Count int
+	Fail  bool
+	Panic bool
+	Delay time.Duration
+}
+
+type ExportProductsHandler struct {
+	stopped chan error
+}
+
+func (h *ExportProductsHandler) Handle(ctx context.Context, query *ExportProducts, yield func(item int) error) error {
+	if query.Panic {
+		panic("boom")
+	}
+
+	for i := 1; i <= query.Count; i++ {
+		select {
+		case <-time.After(query.Delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		if err := yield(i); err != nil {
+			if h.stopped != nil {
+				h.stopped <- err
+			}
+			return err
+		}
+	}
+
+	if query.Fail {
+		return errors.New("export failed")
+	}
+
+	return nil
+}
+
+func streams_cleanup(t *testing.T) {
+	t.Cleanup(func() {
+		streamQueryHandlers = make(map[reflect.Type]interface{})
+		streamBehaviors = make(map[int]interface{})
+	})
+}
+
+func collectStream[TItem any](stream *Stream[TItem]) []TItem {
+	items := []TItem{}
+
+	for item := range stream.Items() {
+		items = append(items, item)
+	}
+
+	return items
+}
+
+func TestRegisterStreamQueryHandler_WhenAlreadyRegistered_ShouldReturnError(t *testing.T) {
+	// arrange
+	defer streams_cleanup(t)
+	RegisterStreamQueryHandler[*ExportProducts, int](&ExportProductsHandler{})
+
+	// act
+	err := RegisterStreamQueryHandler[*ExportProducts, int](&ExportProductsHandler{})
+
+	// assert
+	assert.Error(t, err)
+}
+
+func TestRequestStream_WhenHandlerYieldsItems_ShouldStreamItems(t *testing.T) {
+	// arrange
+	defer streams_cleanup(t)
+	RegisterStreamQueryHandler[*ExportProducts, int](&ExportProductsHandler{})
+
+	// act
+	stream, err := RequestStream[*ExportProducts, int](context.TODO(), &ExportProducts{Count: 3})
+
+	// assert
+	assert.Nil(t, err)
+	assert.Equal(t, []int{1, 2, 3}, collectStream(stream))
+	assert.Nil(t, stream.Err())
+}
+
+func TestRequestStream_WhenHandlerNotFound_ShouldReturnError(t *testing.T) {
+	// act
+	stream, err := RequestStream[*ExportProducts, int](context.TODO(), &ExportProducts{})
+
+	// assert
+	assert.Nil(t, stream)
+	assert.EqualError(t, err, "no handler registered for stream query *cqrs.ExportProducts")
+}
+
+func TestRequestStream_WhenHandlerFails_ShouldReturnErrorAfterItems(t *testing.T) {
+	// arrange
+	defer streams_cleanup(t)
+	RegisterStreamQueryHandler[*ExportProducts, int](&ExportProductsHandler{})
+
+	// act
+	stream, _ := RequestStream[*ExportProducts, int](context.TODO(), &ExportProducts{Count: 2, Fail: true})
+
+	// assert
+	assert.Equal(t, []int{1, 2}, collectStream(stream))
+	assert.EqualError(t, stream.Err(), "export failed")
+}
+
+func TestRequestStream_WhenHandlerPanics_ShouldReturnError(t *testing.T) {
+	// arrange
+	defer streams_cleanup(t)
+	RegisterStreamQueryHandler[*ExportProducts, int](&ExportProductsHandler{})
+
+	// act
+	stream, _ := RequestStream[*ExportProducts, int](context.TODO(), &ExportProducts{Panic: true})
+
+	// assert
+	assert.Empty(t, collectStream(stream))
+	assert.EqualError(t, stream.Err(), "stream query of type *cqrs.ExportProducts panicked: boom")
+}
+
+func TestStream_WhenClosedEarly_ShouldStopHandler(t *testing.T) {
+	// arrange
+	defer streams_cleanup(t)
+	handler := &ExportProductsHandler{stopped: make(chan error, 1)}
+	RegisterStreamQueryHandler[*ExportProducts, int](handler)
+	stream, _ := RequestStream[*ExportProducts, int](context.TODO(), &ExportProducts{Count: 100})
+
+	// act
+	first := <-stream.Items()
+	err := stream.Close()
+
+	// assert
+	assert.Equal(t, 1, first)
+	assert.Nil(t, err)
+	assert.ErrorIs(t, <-handler.stopped, context.Canceled)
+}
+
+func TestRequestStream_WhenContextCanceled_ShouldReturnContextError(t *testing.T) {
+	// arrange
+	defer streams_cleanup(t)
+	RegisterStreamQueryHandler[*ExportProducts, int](&ExportProductsHandler{})
+	ctx, cancel := context.WithCancel(context.TODO())
+	stream, _ := RequestStream[*ExportProducts, int](ctx, &ExportProducts{Count: 100, Delay: time.Millisecond})
+
+	// act
+	<-stream.Items()
+	cancel()
+	collectStream(stream)
+
+	// assert
+	assert.ErrorIs(t, stream.Err(), context.Canceled)
+}
+
+func TestStreamItemTimeoutBehavior_WhenItemIsLate_ShouldReturnTimeoutError(t *testing.T) {
+	// arrange
+	defer streams_cleanup(t)
+	RegisterStreamQueryHandler[*ExportProducts, int](&ExportProductsHandler{})
+	RegisterStreamBehavior(0, NewStreamItemTimeoutBehavior(10*time.Millisecond))
+
+	// act
+	stream, _ := RequestStream[*ExportProducts, int](context.TODO(), &ExportProducts{Count: 1, Delay: time.Second})
+	items := collectStream(stream)
+
+	// assert
+	assert.Empty(t, items)
+	assert.ErrorIs(t, stream.Err(), ErrTimeout)
+}
+
+func TestStreamItemTimeoutBehavior_WhenItemsAreOnTime_ShouldStreamItems(t *testing.T) {
+	// arrange
+	defer streams_cleanup(t)
+	RegisterStreamQueryHandler[*ExportProducts, int](&ExportProductsHandler{})
+	RegisterStreamBehavior(0, NewStreamItemTimeoutBehavior(time.Second))
+
+	// act
+	stream, _ := RequestStream[*ExportProducts, int](context.TODO(), &ExportProducts{Count: 3})
+
+	// assert
+	assert.Equal(t, []int{1, 2, 3}, collectStream(stream))
+	assert.Nil(t, stream.Err())
+}
+
+func TestStreamLoggingBehavior_WhenStreamCompletes_ShouldLogItemCount(t *testing.T) {
+	// arrange
+	defer streams_cleanup(t)
+	buffer := &bytes.Buffer{}
+	RegisterStreamQueryHandler[*ExportProducts, int](&ExportProductsHandler{})
+	RegisterStreamBehavior(0, NewStreamLoggingBehavior(newJSONLogger(buffer), LogPayload(false)))
+
+	// act
+	stream, _ := RequestStream[*ExportProducts, int](context.TODO(), &ExportProducts{Count: 3})
+	collectStream(stream)
+	stream.Err()
+
+	// assert
+	line := decodeLogLine(t, buffer)
+	assert.Equal(t, "query handled", line["msg"])
+	assert.Equal(t, float64(3), line["items"])
+}
+
+type ExportProductsPolicy struct {
+}
+
+func (p *ExportProductsPolicy) Authorize(ctx context.Context, principal Principal, query *ExportProducts) (bool, error) {
+	return false, nil
+}
+
+type ExportProductsValidator struct {
+}
+
+func (v *ExportProductsValidator) Validate(ctx context.Context, query *ExportProducts) error {
+	if query.Count <= 0 {
+		return FieldError{Field: "Count", Message: "must be positive"}
+	}
+
+	return nil
+}
+
+func TestRequestStream_WhenPolicyForbids_ShouldFailStreamWithoutItems(t *testing.T) {
+	// arrange
+	defer streams_cleanup(t)
+	defer authorization_cleanup(t)
+	RegisterStreamQueryHandler[*ExportProducts, int](&ExportProductsHandler{})
+	RegisterAuthorizationPolicy[*ExportProducts](&ExportProductsPolicy{})
+	RegisterStreamBehavior(0, NewStreamAuthorizationBehavior())
+	ctx := WithPrincipal(context.TODO(), &FakePrincipal{ID: "1"})
+
+	// act
+	stream, err := RequestStream[*ExportProducts, int](ctx, &ExportProducts{Count: 3})
+	items := collectStream(stream)
+
+	// assert
+	assert.Nil(t, err)
+	assert.Empty(t, items)
+	assert.True(t, errors.Is(stream.Err(), ErrForbidden))
+}
+
+func TestRequestStream_WhenValidationFails_ShouldFailStreamWithoutItems(t *testing.T) {
+	// arrange
+	defer streams_cleanup(t)
+	defer validation_cleanup(t)
+	RegisterStreamQueryHandler[*ExportProducts, int](&ExportProductsHandler{})
+	RegisterValidator[*ExportProducts](&ExportProductsValidator{})
+	RegisterStreamBehavior(0, NewStreamValidationBehavior())
+
+	// act
+	stream, err := RequestStream[*ExportProducts, int](context.TODO(), &ExportProducts{Count: 0, Fail: true})
+	items := collectStream(stream)
+
+	// assert
+	var validationErr *ValidationError
+	assert.Nil(t, err)
+	assert.Empty(t, items)
+	assert.True(t, errors.As(stream.Err(), &validationErr))
+}
+
+func TestRequestStream_WhenChecksPass_ShouldStreamItems(t *testing.T) {
+	// arrange
+	defer streams_cleanup(t)
+	defer validation_cleanup(t)
+	RegisterStreamQueryHandler[*ExportProducts, int](&ExportProductsHandler{})
+	RegisterValidator[*ExportProducts](&ExportProductsValidator{})
+	RegisterStreamBehavior(0, NewStreamAuthorizationBehavior())
+	RegisterStreamBehavior(1, NewStreamValidationBehavior())
+
+	// act
+	stream, _ := RequestStream[*ExportProducts, int](context.TODO(), &ExportProducts{Count: 2})
+	items := collectStream(stream)
+
+	// assert
+	assert.Equal(t, []int{1, 2}, items)
+	assert.Nil(t, stream.Err())
+}
@@ -0,0 +1,151 @@
+package cqrs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+type WithTimeout interface {
+	Timeout() time.Duration
+}
+
+var ErrTimeout = errors.New("request timed out")
+
+type TimeoutError struct {
+	MessageIDs
+	RequestType string
+	Timeout     time.Duration
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("request of type %s timed out after %s", e.RequestType, e.Timeout)
+}
+
+func (e *TimeoutError) Unwrap() error {
+	return ErrTimeout
+}
+
+type LateCompletionHook func(ctx context.Context, request interface{}, response interface{}, err error, elapsed time.Duration)
+
+type TimeoutOption func(behavior *TimeoutBehavior)
+
+func TimeoutFor[TRequest any](timeout time.Duration) TimeoutOption {
+	var request TRequest
+	requestType := reflect.TypeOf(request)
+
+	return func(behavior *TimeoutBehavior) {
+		behavior.timeouts[requestType] = timeout
+	}
+}
+
+func OnLateCompletion(hook LateCompletionHook) TimeoutOption {
+	return func(behavior *TimeoutBehavior) {
+		behavior.onLateCompletion = hook
+	}
+}
+
+type TimeoutBehavior struct {
+	timeout          time.Duration
+	timeouts         map[reflect.Type]time.Duration
+	onLateCompletion LateCompletionHook
+}
+
+func NewTimeoutBehavior(timeout time.Duration, options ...TimeoutOption) *TimeoutBehavior {
+	behavior := &TimeoutBehavior{
+		timeout:  timeout,
+		timeouts: make(map[reflect.Type]time.Duration),
+	}
+
+	for _, option := range options {
+		option(behavior)
+	}
+
+	return behavior
+}
+
+type timeoutResult struct {
+	response interface{}
+	err      error
+	panicked interface{}
+}
+
+func (b *TimeoutBehavior) Handle(ctx context.Context, request interface{}, next PipelineFunc) (interface{}, error) {
+	timeout := b.timeoutFor(request)
+
+	if timeout <= 0 {
+		return next(ctx, request)
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	started := time.Now()
+	done := make(chan timeoutResult, 1)
+
+	go func() {
+		result := timeoutResult{}
+
+		defer func() {
+			if p := recover(); p != nil {
+				result.panicked = p
+				result.err = fmt.Errorf("request of type %T panicked: %v", request, p)
+			}
+			done <- result
+		}()
+
+		result.response, result.err = next(timeoutCtx, request)
+	}()
+
+	select {
+	case result := <-done:
+		if result.panicked != nil {
+			panic(result.panicked)
+		}
+		return result.response, result.err
+	case <-timeoutCtx.Done():
+	}
+
+	b.reportLateCompletion(ctx, request, started, done)
+
+	if errors.Is(ctx.Err(), context.Canceled) {
+		return nil, ctx.Err()
+	}
+
+	timeoutErr := &TimeoutError{
+		MessageIDs:  MessageIDsFromContext(ctx),
+		RequestType: reflect.TypeOf(request).String(),
+		Timeout:     timeout,
+	}
+
+	return nil, timeoutErr
+}
+
+func (b *TimeoutBehavior) timeoutFor(request interface{}) time.Duration {
+	withTimeout, ok := request.(WithTimeout)
+
+	if ok && withTimeout.Timeout() > 0 {
+		return withTimeout.Timeout()
+	}
+
+	timeout, found := b.timeouts[reflect.TypeOf(request)]
+
+	if found {
+		return timeout
+	}
+
+	return b.timeout
+}
+
+func (b *TimeoutBehavior) reportLateCompletion(ctx context.Context, request interface{}, started time.Time, done chan timeoutResult) {
+	if b.onLateCompletion == nil {
+		return
+	}
+
+	go func() {
+		result := <-done
+		b.onLateCompletion(ctx, request, result.response, result.err, time.Since(started))
+	}()
+}
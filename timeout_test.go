@@ -0,0 +1,151 @@
+package cqrs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type SlowCommand struct {
+	Delay time.Duration
+}
+
+type SlowCommandHandler struct {
+}
+
+func (h *SlowCommandHandler) Handle(ctx context.Context, command *SlowCommand) (*Response, error) {
+	time.Sleep(command.Delay)
+	return &Response{}, nil
+}
+
+type TimedCommand struct {
+}
+
+func (c *TimedCommand) Timeout() time.Duration {
+	return time.Second
+}
+
+func TestTimeoutBehavior_WhenHandlerExceedsTimeout_ShouldReturnErrTimeout(t *testing.T) {
+	// arrange
+	defer commands_cleanup(t)
+	defer behaviors_cleanup(t)
+	RegisterCommandHandler[*SlowCommand, *Response](&SlowCommandHandler{})
+	RegisterCommandPipelineBehavior(0, NewTimeoutBehavior(10*time.Millisecond))
+
+	// act
+	res, err := Send[*SlowCommand, *Response](context.TODO(), &SlowCommand{Delay: 100 * time.Millisecond})
+
+	// assert
+	var timeoutErr *TimeoutError
+	assert.Nil(t, res)
+	assert.True(t, errors.Is(err, ErrTimeout))
+	assert.True(t, errors.As(err, &timeoutErr))
+	assert.Equal(t, 10*time.Millisecond, timeoutErr.Timeout)
+}
+
+func TestTimeoutBehavior_WhenHandlerCompletesInTime_ShouldReturnResponse(t *testing.T) {
+	// arrange
+	defer commands_cleanup(t)
+	defer behaviors_cleanup(t)
+	RegisterCommandHandler[*SlowCommand, *Response](&SlowCommandHandler{})
+	RegisterCommandPipelineBehavior(0, NewTimeoutBehavior(time.Second))
+
+	// act
+	res, err := Send[*SlowCommand, *Response](context.TODO(), &SlowCommand{})
+
+	// assert
+	assert.Nil(t, err)
+	assert.Equal(t, &Response{}, res)
+}
+
+func TestTimeoutBehavior_WhenHandlerCompletesLate_ShouldCallHook(t *testing.T) {
+	// arrange
+	completed := make(chan time.Duration, 1)
+	hook := func(ctx context.Context, request interface{}, response interface{}, err error, elapsed time.Duration) {
+		completed <- elapsed
+	}
+	behavior := NewTimeoutBehavior(10*time.Millisecond, OnLateCompletion(hook))
+	next := func(ctx context.Context, request interface{}) (interface{}, error) {
+		time.Sleep(50 * time.Millisecond)
+		return &Response{}, nil
+	}
+
+	// act
+	_, err := behavior.Handle(context.TODO(), &SlowCommand{}, next)
+
+	// assert
+	assert.True(t, errors.Is(err, ErrTimeout))
+	select {
+	case elapsed := <-completed:
+		assert.GreaterOrEqual(t, elapsed, 50*time.Millisecond)
+	case <-time.After(time.Second):
+		t.Fatal("late completion hook was not called")
+	}
+}
+
+func TestTimeoutBehavior_WhenContextCanceled_ShouldReturnContextError(t *testing.T) {
+	// arrange
+	behavior := NewTimeoutBehavior(time.Second)
+	ctx, cancel := context.WithCancel(context.TODO())
+	next := func(ctx context.Context, request interface{}) (interface{}, error) {
+		cancel()
+		time.Sleep(50 * time.Millisecond)
+		return &Response{}, nil
+	}
+
+	// act
+	_, err := behavior.Handle(ctx, &SlowCommand{}, next)
+
+	// assert
+	assert.True(t, errors.Is(err, context.Canceled))
+}
+
+func TestTimeoutBehavior_WhenHandlerPanics_ShouldPanicOnCaller(t *testing.T) {
+	// arrange
+	behavior := NewTimeoutBehavior(time.Second)
+	next := func(ctx context.Context, request interface{}) (interface{}, error) {
+		panic("boom")
+	}
+
+	// act
+	handle := func() {
+		behavior.Handle(context.TODO(), &SlowCommand{}, next)
+	}
+
+	// assert
+	assert.PanicsWithValue(t, "boom", handle)
+}
+
+func TestTimeoutBehavior_WhenTimeoutResolved_ShouldPreferRequestThenTypeThenDefault(t *testing.T) {
+	// arrange
+	behavior := NewTimeoutBehavior(time.Minute, TimeoutFor[*SlowCommand](time.Hour))
+
+	// act
+	requestTimeout := behavior.timeoutFor(&TimedCommand{})
+	typeTimeout := behavior.timeoutFor(&SlowCommand{})
+	defaultTimeout := behavior.timeoutFor(&Command1{})
+
+	// assert
+	assert.Equal(t, time.Second, requestTimeout)
+	assert.Equal(t, time.Hour, typeTimeout)
+	assert.Equal(t, time.Minute, defaultTimeout)
+}
+
+func TestTimeoutBehavior_WhenCallingNext_ShouldPassContextWithDeadline(t *testing.T) {
+	// arrange
+	behavior := NewTimeoutBehavior(time.Second)
+	hasDeadline := false
+	next := func(ctx context.Context, request interface{}) (interface{}, error) {
+		_, hasDeadline = ctx.Deadline()
+		return nil, nil
+	}
+
+	// act
+	behavior.Handle(context.TODO(), &SlowCommand{}, next)
+
+	// assert
+	assert.True(t, hasDeadline)
+}
@@ -0,0 +1,205 @@
+package cqrs
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+
+	"go.uber.org/multierr"
+)
+
+type txContextKey struct{}
+
+func WithTx(ctx context.Context, tx *sql.Tx) context.Context {
+	return context.WithValue(ctx, txContextKey{}, tx)
+}
+
+func TxFromContext(ctx context.Context) (*sql.Tx, bool) {
+	tx, ok := ctx.Value(txContextKey{}).(*sql.Tx)
+	return tx, ok && tx != nil
+}
+
+type EventPublisher func(ctx context.Context, event interface{}) error
+
+func SyncEventPublisher(ctx context.Context, event interface{}) error {
+	return PublishEvent(ctx, event)
+}
+
+func AsyncEventPublisher(ctx context.Context, event interface{}) error {
+	return PublishEventAsync(ctx, event)
+}
+
+type TransactionOption func(behavior *TransactionBehavior)
+
+func TransactionIsolation(level sql.IsolationLevel) TransactionOption {
+	return func(behavior *TransactionBehavior) {
+		behavior.options.Isolation = level
+	}
+}
+
+func IsolationFor[TRequest any](level sql.IsolationLevel) TransactionOption {
+	var request TRequest
+	requestType := reflect.TypeOf(request)
+
+	return func(behavior *TransactionBehavior) {
+		behavior.isolation[requestType] = level
+	}
+}
+
+func TransactionReadOnly() TransactionOption {
+	return func(behavior *TransactionBehavior) {
+		behavior.options.ReadOnly = true
+	}
+}
+
+func PublishEventsAfterCommit(publisher EventPublisher) TransactionOption {
+	return func(behavior *TransactionBehavior) {
+		behavior.publish = publisher
+	}
+}
+
+type TransactionBehavior struct {
+	db        *sql.DB
+	options   sql.TxOptions
+	isolation map[reflect.Type]sql.IsolationLevel
+	publish   EventPublisher
+}
+
+func NewTransactionBehavior(db *sql.DB, options ...TransactionOption) (*TransactionBehavior, error) {
+	if db == nil {
+		return nil, errors.New("a database handle must be provided")
+	}
+
+	behavior := &TransactionBehavior{
+		db:        db,
+		isolation: make(map[reflect.Type]sql.IsolationLevel),
+	}
+
+	for _, option := range options {
+		option(behavior)
+	}
+
+	return behavior, nil
+}
+
+func (b *TransactionBehavior) Handle(ctx context.Context, request interface{}, next PipelineFunc) (res interface{}, err error) {
+	if _, found := TxFromContext(ctx); found {
+		return next(ctx, request)
+	}
+
+	options := b.txOptionsFor(request)
+
+	tx, err := b.db.BeginTx(ctx, &options)
+
+	if err != nil {
+		return nil, err
+	}
+
+	committed := false
+
+	defer func() {
+		if committed {
+			return
+		}
+
+		tx.Rollback()
+		clearEvents(res)
+	}()
+
+	res, err = next(WithTx(ctx, tx), request)
+
+	if err != nil {
+		return res, err
+	}
+
+	err = tx.Commit()
+
+	if err != nil {
+		return nil, err
+	}
+
+	committed = true
+
+	return res, b.publishEvents(ctx, res)
+}
+
+func (b *TransactionBehavior) CheckBatchItem(ctx context.Context, request interface{}) error {
+	return nil
+}
+
+func (b *TransactionBehavior) txOptionsFor(request interface{}) sql.TxOptions {
+	options := b.options
+
+	level, found := lookupRequestType(b.isolation, request)
+
+	if found {
+		options.Isolation = level
+	}
+
+	return options
+}
+
+func (b *TransactionBehavior) publishEvents(ctx context.Context, res interface{}) error {
+	if b.publish == nil {
+		return nil
+	}
+
+	if batch, ok := res.(batchResponses); ok {
+		var err error = nil
+
+		for _, response := range batch.succeeded() {
+			err = multierr.Append(err, b.publishEvents(ctx, response))
+		}
+
+		return err
+	}
+
+	notifiable, ok := asNotifiable(res)
+
+	if !ok {
+		return nil
+	}
+
+	var err error = nil
+
+	for _, event := range notifiable.GetEvents() {
+		err = multierr.Append(err, b.publish(ctx, event))
+	}
+
+	notifiable.ClearEvents()
+
+	return err
+}
+
+func clearEvents(res interface{}) {
+	if batch, ok := res.(batchResponses); ok {
+		for _, response := range batch.succeeded() {
+			clearEvents(response)
+		}
+
+		return
+	}
+
+	notifiable, ok := asNotifiable(res)
+
+	if ok {
+		notifiable.ClearEvents()
+	}
+}
+
+func asNotifiable(res interface{}) (INotifiable, bool) {
+	notifiable, ok := res.(INotifiable)
+
+	if !ok {
+		return nil, false
+	}
+
+	value := reflect.ValueOf(notifiable)
+
+	if value.Kind() == reflect.Ptr && value.IsNil() {
+		return nil, false
+	}
+
+	return notifiable, true
+}
@@ -0,0 +1,288 @@
+package cqrs
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type CreateOrder struct {
+	ID   string
+	Fail bool
+}
+
+type Order struct {
+	ID     string
+	events []interface{}
+}
+
+func (o *Order) AddEvent(event interface{}) {
+	o.events = append(o.events, event)
+}
+
+func (o *Order) ClearEvents() {
+	o.events = []interface{}{}
+}
+
+func (o *Order) GetEvents() []interface{} {
+	return o.events
+}
+
+type OrderCreated struct {
+	ID string
+}
+
+type CreateOrderHandler struct {
+	panics bool
+}
+
+func (h *CreateOrderHandler) Handle(ctx context.Context, command *CreateOrder) (*Order, error) {
+	tx, ok := TxFromContext(ctx)
+
+	if !ok {
+		return nil, errors.New("no transaction")
+	}
+
+	_, err := tx.ExecContext(ctx, "INSERT INTO orders (id) VALUES (?)", command.ID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if h.panics {
+		panic("boom")
+	}
+
+	order := &Order{ID: command.ID}
+	order.AddEvent(&OrderCreated{ID: command.ID})
+
+	if command.Fail {
+		return order, errors.New("failed")
+	}
+
+	return order, nil
+}
+
+type OrderCreatedHandler struct {
+	db     *sql.DB
+	orders int
+}
+
+func (h *OrderCreatedHandler) Handle(ctx context.Context, event *OrderCreated) error {
+	return h.db.QueryRowContext(ctx, "SELECT COUNT(1) FROM orders WHERE id = ?", event.ID).Scan(&h.orders)
+}
+
+func newOrdersDB(t *testing.T) *sql.DB {
+	db, err := sql.Open("sqlite", ":memory:")
+	assert.Nil(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() {
+		db.Close()
+	})
+
+	_, err = db.Exec("CREATE TABLE orders (id TEXT PRIMARY KEY)")
+	assert.Nil(t, err)
+
+	return db
+}
+
+func countOrders(t *testing.T, db *sql.DB) int {
+	var count int
+	err := db.QueryRow("SELECT COUNT(1) FROM orders").Scan(&count)
+	assert.Nil(t, err)
+	return count
+}
+
+func newTransactionBehavior(t *testing.T, db *sql.DB, options ...TransactionOption) *TransactionBehavior {
+	behavior, err := NewTransactionBehavior(db, options...)
+	assert.Nil(t, err)
+	return behavior
+}
+
+func TestNewTransactionBehavior_WhenDatabaseIsNil_ShouldReturnError(t *testing.T) {
+	// act
+	_, err := NewTransactionBehavior(nil)
+
+	// assert
+	assert.Error(t, err)
+}
+
+func TestTransactionBehavior_WhenHandlerSucceeds_ShouldCommit(t *testing.T) {
+	// arrange
+	defer commands_cleanup(t)
+	defer behaviors_cleanup(t)
+	db := newOrdersDB(t)
+	RegisterCommandHandler[*CreateOrder, *Order](&CreateOrderHandler{})
+	RegisterCommandPipelineBehavior(0, newTransactionBehavior(t, db))
+
+	// act
+	order, err := Send[*CreateOrder, *Order](context.TODO(), &CreateOrder{ID: "1"})
+
+	// assert
+	assert.Nil(t, err)
+	assert.Equal(t, "1", order.ID)
+	assert.Equal(t, 1, countOrders(t, db))
+}
+
+func TestTransactionBehavior_WhenHandlerFails_ShouldRollbackAndClearEvents(t *testing.T) {
+	// arrange
+	defer commands_cleanup(t)
+	defer behaviors_cleanup(t)
+	db := newOrdersDB(t)
+	RegisterCommandHandler[*CreateOrder, *Order](&CreateOrderHandler{})
+	RegisterCommandPipelineBehavior(0, newTransactionBehavior(t, db))
+
+	// act
+	order, err := Send[*CreateOrder, *Order](context.TODO(), &CreateOrder{ID: "1", Fail: true})
+
+	// assert
+	assert.Error(t, err)
+	assert.Empty(t, order.GetEvents())
+	assert.Equal(t, 0, countOrders(t, db))
+}
+
+func TestTransactionBehavior_WhenHandlerPanics_ShouldRollbackAndPanic(t *testing.T) {
+	// arrange
+	defer commands_cleanup(t)
+	defer behaviors_cleanup(t)
+	db := newOrdersDB(t)
+	RegisterCommandHandler[*CreateOrder, *Order](&CreateOrderHandler{panics: true})
+	RegisterCommandPipelineBehavior(0, newTransactionBehavior(t, db))
+
+	// act
+	send := func() {
+		Send[*CreateOrder, *Order](context.TODO(), &CreateOrder{ID: "1"})
+	}
+
+	// assert
+	assert.PanicsWithValue(t, "boom", send)
+	assert.Equal(t, 0, countOrders(t, db))
+}
+
+func TestTransactionBehavior_WhenPublishingEventsAfterCommit_ShouldSeeCommittedData(t *testing.T) {
+	// arrange
+	defer commands_cleanup(t)
+	defer behaviors_cleanup(t)
+	defer events_cleanup(t)
+	db := newOrdersDB(t)
+	eventHandler := &OrderCreatedHandler{db: db}
+	RegisterEventSubscriber[*OrderCreated](eventHandler)
+	RegisterCommandHandler[*CreateOrder, *Order](&CreateOrderHandler{})
+	RegisterCommandPipelineBehavior(0, newTransactionBehavior(t, db, PublishEventsAfterCommit(SyncEventPublisher)))
+
+	// act
+	order, err := Send[*CreateOrder, *Order](context.TODO(), &CreateOrder{ID: "1"})
+
+	// assert
+	assert.Nil(t, err)
+	assert.Equal(t, 1, eventHandler.orders)
+	assert.Empty(t, order.GetEvents())
+}
+
+func TestTransactionBehavior_WhenTransactionInContext_ShouldJoinTransaction(t *testing.T) {
+	// arrange
+	db := newOrdersDB(t)
+	behavior := newTransactionBehavior(t, db)
+	tx, _ := db.Begin()
+	defer tx.Rollback()
+	var received *sql.Tx
+	next := func(ctx context.Context, request interface{}) (interface{}, error) {
+		received, _ = TxFromContext(ctx)
+		return nil, nil
+	}
+
+	// act
+	_, err := behavior.Handle(WithTx(context.TODO(), tx), &CreateOrder{}, next)
+
+	// assert
+	assert.Nil(t, err)
+	assert.Same(t, tx, received)
+}
+
+func TestTransactionBehavior_WhenIsolationForTypeRegistered_ShouldUseTypeIsolation(t *testing.T) {
+	// arrange
+	db := newOrdersDB(t)
+	behavior := newTransactionBehavior(
+		t,
+		db,
+		TransactionIsolation(sql.LevelReadCommitted),
+		TransactionReadOnly(),
+		IsolationFor[*CreateOrder](sql.LevelSerializable),
+	)
+
+	// act
+	typeOptions := behavior.txOptionsFor(&CreateOrder{})
+	defaultOptions := behavior.txOptionsFor(&Command1{})
+
+	// assert
+	assert.Equal(t, sql.TxOptions{Isolation: sql.LevelSerializable, ReadOnly: true}, typeOptions)
+	assert.Equal(t, sql.TxOptions{Isolation: sql.LevelReadCommitted, ReadOnly: true}, defaultOptions)
+}
+
+type CreateOrderBatchHandler struct {
+}
+
+type OrderCreatedCounter struct {
+	calls int
+}
+
+func (h *OrderCreatedCounter) Handle(ctx context.Context, event *OrderCreated) error {
+	h.calls++
+	return nil
+}
+
+func (h *CreateOrderBatchHandler) HandleBatch(ctx context.Context, commands []*CreateOrder) (BatchResult[*Order], error) {
+	tx, _ := TxFromContext(ctx)
+	result := make(BatchResult[*Order], len(commands))
+
+	for i, command := range commands {
+		if command.Fail {
+			result[i].Err = errors.New("failed")
+			continue
+		}
+
+		tx.ExecContext(ctx, "INSERT INTO orders (id) VALUES (?)", command.ID)
+		order := &Order{ID: command.ID}
+		order.AddEvent(&OrderCreated{ID: command.ID})
+		result[i].Response = order
+	}
+
+	return result, nil
+}
+
+func TestTransactionBehavior_WhenBatchCommitted_ShouldPublishEventsOfEachCommand(t *testing.T) {
+	// arrange
+	defer behaviors_cleanup(t)
+	defer events_cleanup(t)
+	defer batch_cleanup(t)
+	db := newOrdersDB(t)
+	counter := &OrderCreatedCounter{}
+	RegisterEventSubscriber[*OrderCreated](counter)
+	RegisterBatchCommandHandler[*CreateOrder, *Order](&CreateOrderBatchHandler{})
+	RegisterCommandPipelineBehavior(0, newTransactionBehavior(t, db, PublishEventsAfterCommit(SyncEventPublisher)))
+
+	// act
+	result, err := SendBatch[*CreateOrder, *Order](context.TODO(), []*CreateOrder{{ID: "1"}, {ID: "2", Fail: true}, {ID: "3"}})
+
+	// assert
+	assert.Nil(t, err)
+	assert.Equal(t, 1, result.Failed())
+	assert.Equal(t, 2, counter.calls)
+	assert.Equal(t, 2, countOrders(t, db))
+	assert.Empty(t, result[0].Response.GetEvents())
+}
+
+func TestTransactionBehavior_WhenBatchOfTypeWithIsolation_ShouldUseTypeIsolation(t *testing.T) {
+	// arrange
+	db := newOrdersDB(t)
+	behavior := newTransactionBehavior(t, db, IsolationFor[*CreateOrder](sql.LevelSerializable))
+
+	// act
+	options := behavior.txOptionsFor([]*CreateOrder{{}})
+
+	// assert
+	assert.Equal(t, sql.LevelSerializable, options.Isolation)
+}
@@ -0,0 +1,174 @@
+package cqrs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+type Validatable interface {
+	Validate(ctx context.Context) error
+}
+
+type IValidator[TRequest any] interface {
+	Validate(ctx context.Context, request TRequest) error
+}
+
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+func (e FieldError) Error() string {
+	if e.Field == "" {
+		return e.Message
+	}
+
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+type ValidationError struct {
+	Errors []FieldError
+}
+
+func NewValidationError(errs ...FieldError) *ValidationError {
+	return &ValidationError{
+		Errors: errs,
+	}
+}
+
+func (e *ValidationError) Add(field string, message string) {
+	e.Errors = append(e.Errors, FieldError{Field: field, Message: message})
+}
+
+func (e *ValidationError) HasErrors() bool {
+	return len(e.Errors) > 0
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+
+	for _, fieldError := range e.Errors {
+		messages = append(messages, fieldError.Error())
+	}
+
+	return fmt.Sprintf("validation failed: %s", strings.Join(messages, "; "))
+}
+
+var validators map[reflect.Type][]interface{}
+
+func init() {
+	validators = make(map[reflect.Type][]interface{})
+}
+
+func RegisterValidator[TRequest any](validator IValidator[TRequest]) error {
+	if validator == nil {
+		return errors.New("a validator must be provided")
+	}
+
+	var request TRequest
+	requestType := reflect.TypeOf(request)
+
+	validators[requestType] = append(validators[requestType], validator)
+
+	return nil
+}
+
+type ValidationBehavior struct {
+}
+
+func NewValidationBehavior() *ValidationBehavior {
+	return &ValidationBehavior{}
+}
+
+func (b *ValidationBehavior) Handle(ctx context.Context, request interface{}, next PipelineFunc) (interface{}, error) {
+	err := Validate(ctx, request)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return next(ctx, request)
+}
+
+func (b *ValidationBehavior) CheckBatchItem(ctx context.Context, request interface{}) error {
+	return Validate(ctx, request)
+}
+
+type StreamValidationBehavior struct {
+}
+
+func NewStreamValidationBehavior() *StreamValidationBehavior {
+	return &StreamValidationBehavior{}
+}
+
+func (b *StreamValidationBehavior) Handle(ctx context.Context, query interface{}, emit StreamEmitFunc, next StreamFunc) error {
+	err := Validate(ctx, query)
+
+	if err != nil {
+		return err
+	}
+
+	return next(ctx, query, emit)
+}
+
+func Validate(ctx context.Context, request interface{}) error {
+	validationErr := &ValidationError{}
+
+	validatable, ok := request.(Validatable)
+
+	if ok {
+		err := collectValidationError(validationErr, validatable.Validate(ctx))
+
+		if err != nil {
+			return err
+		}
+	}
+
+	args := []reflect.Value{
+		reflect.ValueOf(ctx),
+		reflect.ValueOf(request),
+	}
+
+	for _, validator := range validators[reflect.TypeOf(request)] {
+		r := reflect.ValueOf(validator).MethodByName("Validate").Call(args)
+
+		validateErr, _ := r[0].Interface().(error)
+
+		err := collectValidationError(validationErr, validateErr)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	if validationErr.HasErrors() {
+		return validationErr
+	}
+
+	return nil
+}
+
+func collectValidationError(validationErr *ValidationError, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var target *ValidationError
+
+	if errors.As(err, &target) {
+		validationErr.Errors = append(validationErr.Errors, target.Errors...)
+		return nil
+	}
+
+	var fieldErr FieldError
+
+	if errors.As(err, &fieldErr) {
+		validationErr.Errors = append(validationErr.Errors, fieldErr)
+		return nil
+	}
+
+	return err
+}
@@ -0,0 +1,138 @@
+package cqrs
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type ValidatableCommand struct {
+	Name  string
+	Price int
+}
+
+func (c *ValidatableCommand) Validate(ctx context.Context) error {
+	if c.Name == "" {
+		return NewValidationError(FieldError{Field: "Name", Message: "is required"})
+	}
+
+	return nil
+}
+
+type ValidatableCommandHandler struct {
+	calls int
+}
+
+func (h *ValidatableCommandHandler) Handle(ctx context.Context, command *ValidatableCommand) (*Response, error) {
+	h.calls++
+	return &Response{}, nil
+}
+
+type PriceValidator struct {
+}
+
+func (v *PriceValidator) Validate(ctx context.Context, command *ValidatableCommand) error {
+	if command.Price <= 0 {
+		return FieldError{Field: "Price", Message: "must be positive"}
+	}
+
+	return nil
+}
+
+type FailingValidator struct {
+}
+
+func (v *FailingValidator) Validate(ctx context.Context, command *ValidatableCommand) error {
+	return errors.New("lookup failed")
+}
+
+func validation_cleanup(t *testing.T) {
+	t.Cleanup(func() {
+		validators = make(map[reflect.Type][]interface{})
+	})
+}
+
+func TestRegisterValidator_WhenValidatorProvided_ShouldAddValidatorToMap(t *testing.T) {
+	// arrange
+	defer validation_cleanup(t)
+	var command *ValidatableCommand
+	requestType := reflect.TypeOf(command)
+	validator := &PriceValidator{}
+
+	// act
+	err := RegisterValidator[*ValidatableCommand](validator)
+
+	// assert
+	assert.Nil(t, err)
+	assert.Contains(t, validators[requestType], validator)
+}
+
+func TestValidationBehavior_WhenRequestIsInvalid_ShouldNotCallHandler(t *testing.T) {
+	// arrange
+	defer commands_cleanup(t)
+	defer behaviors_cleanup(t)
+	handler := &ValidatableCommandHandler{}
+	RegisterCommandHandler[*ValidatableCommand, *Response](handler)
+	RegisterCommandPipelineBehavior(0, NewValidationBehavior())
+
+	// act
+	res, err := Send[*ValidatableCommand, *Response](context.TODO(), &ValidatableCommand{Price: 1})
+
+	// assert
+	var validationErr *ValidationError
+	assert.True(t, errors.As(err, &validationErr))
+	assert.Equal(t, []FieldError{{Field: "Name", Message: "is required"}}, validationErr.Errors)
+	assert.Nil(t, res)
+	assert.Equal(t, 0, handler.calls)
+}
+
+func TestValidationBehavior_WhenRequestIsValid_ShouldCallHandler(t *testing.T) {
+	// arrange
+	defer commands_cleanup(t)
+	defer behaviors_cleanup(t)
+	defer validation_cleanup(t)
+	handler := &ValidatableCommandHandler{}
+	RegisterCommandHandler[*ValidatableCommand, *Response](handler)
+	RegisterValidator[*ValidatableCommand](&PriceValidator{})
+	RegisterCommandPipelineBehavior(0, NewValidationBehavior())
+
+	// act
+	res, err := Send[*ValidatableCommand, *Response](context.TODO(), &ValidatableCommand{Name: "foo", Price: 1})
+
+	// assert
+	assert.Nil(t, err)
+	assert.Equal(t, &Response{}, res)
+	assert.Equal(t, 1, handler.calls)
+}
+
+func TestValidate_WhenMultipleViolations_ShouldAggregateFieldErrors(t *testing.T) {
+	// arrange
+	defer validation_cleanup(t)
+	RegisterValidator[*ValidatableCommand](&PriceValidator{})
+
+	// act
+	err := Validate(context.TODO(), &ValidatableCommand{})
+
+	// assert
+	var validationErr *ValidationError
+	assert.True(t, errors.As(err, &validationErr))
+	assert.Len(t, validationErr.Errors, 2)
+	assert.Equal(t, "validation failed: Name: is required; Price: must be positive", err.Error())
+}
+
+func TestValidate_WhenValidatorFailsUnexpectedly_ShouldReturnError(t *testing.T) {
+	// arrange
+	defer validation_cleanup(t)
+	RegisterValidator[*ValidatableCommand](&FailingValidator{})
+
+	// act
+	err := Validate(context.TODO(), &ValidatableCommand{Name: "foo"})
+
+	// assert
+	var validationErr *ValidationError
+	assert.False(t, errors.As(err, &validationErr))
+	assert.EqualError(t, err, "lookup failed")
+}